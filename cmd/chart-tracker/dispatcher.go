@@ -2,16 +2,37 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/chartrepo"
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/oci"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/openpgp"
 	"golang.org/x/time/rate"
-	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// repositoryHTTPTimeout represents the timeout used in the requests sent
+	// to chart repositories (index file and charts archives).
+	repositoryHTTPTimeout = 1 * time.Minute
+
+	// repositoryMetadataFile represents the name of the optional metadata file
+	// that chart repositories can publish next to their index file.
+	repositoryMetadataFile = "artifacthub-repo.yml"
 )
 
 // job represents a job for processing a given chart version in the provided
@@ -19,10 +40,16 @@ import (
 // handled by a worker.
 type job struct {
 	repo         *hub.ChartRepository
+	httpClient   *http.Client
+	keyring      openpgp.EntityList
 	chartVersion *repo.ChartVersion
 	downloadLogo bool
 }
 
+// errIndexFileNotModified indicates that the index file of a repository has
+// not changed since the last time it was processed successfully.
+var errIndexFileNotModified = errors.New("index file not modified")
+
 // dispatcher is in charge of generating jobs and dispatching them among the
 // available workers.
 type dispatcher struct {
@@ -30,6 +57,9 @@ type dispatcher struct {
 	ec     *errorsCollector
 	hubAPI *api.API
 	Queue  chan *job
+
+	mu          sync.Mutex
+	indexCaches map[string]*hub.ChartRepositoryIndexCache // K: chart repository id
 }
 
 // newDispatcher creates a new dispatcher instance.
@@ -39,6 +69,8 @@ func newDispatcher(ctx context.Context, ec *errorsCollector, hubAPI *api.API) *d
 		hubAPI: hubAPI,
 		Queue:  make(chan *job),
 		ec:     ec,
+
+		indexCaches: make(map[string]*hub.ChartRepositoryIndexCache),
 	}
 }
 
@@ -64,24 +96,79 @@ func (d *dispatcher) run(wg *sync.WaitGroup, repos []*hub.ChartRepository) {
 
 // trackRepositoryCharts generates jobs for each of the chart versions found in
 // the given repository, provided that that version has not been already
-// processed and its digest has not changed.
+// processed and its digest has not changed. Chart versions previously
+// registered that are no longer available in the repository are unregistered.
 func (d *dispatcher) trackRepositoryCharts(wg *sync.WaitGroup, r *hub.ChartRepository) {
 	defer wg.Done()
 
+	httpClient, err := chartrepo.NewHTTPClient(r, repositoryHTTPTimeout)
+	if err != nil {
+		msg := "Error setting up repository http client"
+		d.ec.append(r.ChartRepositoryID, &hub.TrackingError{
+			Kind:    hub.IndexTrackingError,
+			URL:     r.URL,
+			Message: fmt.Sprintf("%s: %v", msg, err),
+		})
+		log.Error().Err(err).Str("repo", r.Name).Msg(msg)
+		return
+	}
 	log.Info().Str("repo", r.Name).Msg("Loading chart repository index file")
-	indexFile, err := loadIndexFile(r)
+	var indexFile *repo.IndexFile
+	if oci.IsOCI(r.URL) {
+		indexFile, err = d.loadOCIIndexFile(newOCIClient(httpClient, r), r)
+	} else {
+		var indexCache *hub.ChartRepositoryIndexCache
+		indexFile, indexCache, err = d.loadIndexFile(httpClient, r)
+		if errors.Is(err, errIndexFileNotModified) {
+			if indexCache != nil {
+				d.mu.Lock()
+				d.indexCaches[r.ChartRepositoryID] = indexCache
+				d.mu.Unlock()
+			}
+			log.Info().Str("repo", r.Name).Msg("Repository index file not modified, skipping")
+			return
+		}
+		if err == nil {
+			d.mu.Lock()
+			d.indexCaches[r.ChartRepositoryID] = indexCache
+			d.mu.Unlock()
+		}
+	}
 	if err != nil {
 		msg := "Error loading repository index file"
-		d.ec.append(r.ChartRepositoryID, fmt.Errorf("%s: %w", msg, err))
+		d.ec.append(r.ChartRepositoryID, &hub.TrackingError{
+			Kind:    hub.IndexTrackingError,
+			URL:     r.URL,
+			Message: fmt.Sprintf("%s: %v", msg, err),
+		})
 		log.Error().Err(err).Str("repo", r.Name).Msg(msg)
 		return
 	}
+	var keyring openpgp.EntityList
+	if !oci.IsOCI(r.URL) {
+		keyring, err = d.loadKeyring(httpClient, r)
+		if err != nil {
+			msg := "Error loading repository keyring"
+			d.ec.append(r.ChartRepositoryID, &hub.TrackingError{
+				Kind:    hub.ProvenanceTrackingError,
+				URL:     r.URL,
+				Message: fmt.Sprintf("%s: %v", msg, err),
+			})
+			log.Warn().Err(err).Str("repo", r.Name).Msg(msg)
+		}
+	}
 	log.Info().Str("repo", r.Name).Msg("Loading registered packages digest")
 	packagesDigest, err := d.hubAPI.ChartRepositories.GetPackagesDigest(d.ctx, r.ChartRepositoryID)
 	if err != nil {
-		log.Error().Err(err).Str("repo", r.Name).Msg("Error getting repository packages digest")
+		msg := "Error getting repository packages digest"
+		d.ec.append(r.ChartRepositoryID, &hub.TrackingError{
+			Kind:    hub.InternalTrackingError,
+			Message: fmt.Sprintf("%s: %v", msg, err),
+		})
+		log.Error().Err(err).Str("repo", r.Name).Msg(msg)
 		return
 	}
+	availableVersions := make(map[string]struct{})
 	for _, chartVersions := range indexFile.Entries {
 		for i, chartVersion := range chartVersions {
 			var downloadLogo bool
@@ -89,12 +176,20 @@ func (d *dispatcher) trackRepositoryCharts(wg *sync.WaitGroup, r *hub.ChartRepos
 				downloadLogo = true
 			}
 			key := fmt.Sprintf("%s@%s", chartVersion.Metadata.Name, chartVersion.Metadata.Version)
+			availableVersions[key] = struct{}{}
 			if chartVersion.Digest != packagesDigest[key] {
-				d.Queue <- &job{
+				j := &job{
 					repo:         r,
+					httpClient:   httpClient,
+					keyring:      keyring,
 					chartVersion: chartVersion,
 					downloadLogo: downloadLogo,
 				}
+				select {
+				case d.Queue <- j:
+				case <-d.ctx.Done():
+					return
+				}
 			}
 			select {
 			case <-d.ctx.Done():
@@ -103,26 +198,245 @@ func (d *dispatcher) trackRepositoryCharts(wg *sync.WaitGroup, r *hub.ChartRepos
 			}
 		}
 	}
+
+	// Unregister chart versions no longer available in the repository
+	for key := range packagesDigest {
+		if _, ok := availableVersions[key]; ok {
+			continue
+		}
+		parts := strings.SplitN(key, "@", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		p := &hub.Package{
+			Kind:    hub.Chart,
+			Name:    parts[0],
+			Version: parts[1],
+			ChartRepository: &hub.ChartRepository{
+				ChartRepositoryID: r.ChartRepositoryID,
+			},
+		}
+		log.Info().Str("repo", r.Name).Str("package", p.Name).Str("version", p.Version).Msg("Unregistering chart version")
+		if err := d.hubAPI.Packages.Unregister(d.ctx, p); err != nil {
+			d.ec.append(r.ChartRepositoryID, &hub.TrackingError{
+				Kind:         hub.UnregisterTrackingError,
+				ChartName:    p.Name,
+				ChartVersion: p.Version,
+				Message:      fmt.Sprintf("error unregistering package %s version %s: %v", p.Name, p.Version, err),
+			})
+			log.Error().Err(err).Str("repo", r.Name).Str("key", key).Msg("Error unregistering chart version")
+		}
+		select {
+		case <-d.ctx.Done():
+			return
+		default:
+		}
+	}
 }
 
 // loadIndexFile downloads and parses the index file of the provided repository.
-func loadIndexFile(r *hub.ChartRepository) (*repo.IndexFile, error) {
-	repoConfig := &repo.Entry{
-		Name: r.Name,
-		URL:  r.URL,
+// The index file cache of the repository, if available, is used to request the
+// index file conditionally, returning errIndexFileNotModified when it has not
+// changed. The index file cache corresponding to the index file downloaded is
+// returned as well. When the index file is downloaded again but its content
+// has not changed, the refreshed cache is returned along with
+// errIndexFileNotModified if its validators (ETag or Last-Modified) changed,
+// so that they can be used in subsequent requests.
+func (d *dispatcher) loadIndexFile(
+	httpClient *http.Client,
+	r *hub.ChartRepository,
+) (*repo.IndexFile, *hub.ChartRepositoryIndexCache, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.Path = path.Join(u.Path, "index.yaml")
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	prevCache := r.IndexCache
+	if prevCache == nil {
+		prevCache = &hub.ChartRepositoryIndexCache{}
+	}
+	if prevCache.ETag != "" {
+		req.Header.Set("If-None-Match", prevCache.ETag)
+	}
+	if prevCache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", prevCache.LastModified)
+	}
+	resp, err := httpClient.Do(req.WithContext(d.ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil, errIndexFileNotModified
+	default:
+		return nil, nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache := &hub.ChartRepositoryIndexCache{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       fmt.Sprintf("%x", sha256.Sum256(data)),
+	}
+	if cache.SHA256 == prevCache.SHA256 {
+		if cache.ETag == prevCache.ETag && cache.LastModified == prevCache.LastModified {
+			return nil, nil, errIndexFileNotModified
+		}
+		return nil, cache, errIndexFileNotModified
+	}
+	indexFile := &repo.IndexFile{}
+	if err := yaml.Unmarshal(data, indexFile); err != nil {
+		return nil, nil, err
+	}
+	if indexFile.APIVersion == "" {
+		return nil, nil, repo.ErrNoAPIVersion
+	}
+	indexFile.SortEntries()
+	return indexFile, cache, nil
+}
+
+// loadKeyring returns the keyring used to verify the provenance files of the
+// charts in the provided repository. The keyring configured in the repository
+// takes precedence. Otherwise, the keyring published in the repository
+// metadata file (artifacthub-repo.yml), if available, is used.
+func (d *dispatcher) loadKeyring(httpClient *http.Client, r *hub.ChartRepository) (openpgp.EntityList, error) {
+	if r.Keyring != nil && *r.Keyring != "" {
+		return chartrepo.ParseKeyring(*r.Keyring)
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, repositoryMetadataFile)
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
 	}
-	getters := getter.All(&cli.EnvSettings{})
-	chartRepository, err := repo.NewChartRepository(repoConfig, getters)
+	resp, err := httpClient.Do(req.WithContext(d.ctx))
 	if err != nil {
 		return nil, err
 	}
-	path, err := chartRepository.DownloadIndexFile()
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	indexFile, err := repo.LoadIndexFile(path)
+	var md struct {
+		Keyring string `json:"keyring"`
+	}
+	if err := yaml.Unmarshal(data, &md); err != nil {
+		return nil, err
+	}
+	if md.Keyring == "" {
+		return nil, nil
+	}
+	return chartrepo.ParseKeyring(md.Keyring)
+}
+
+// loadOCIIndexFile builds an index file for the provided OCI based repository
+// from the charts and versions (tags) available in the registry. The
+// repository url can point to a single chart or to a namespace containing
+// multiple charts.
+func (d *dispatcher) loadOCIIndexFile(ociClient *oci.Client, r *hub.ChartRepository) (*repo.IndexFile, error) {
+	ref, err := oci.ParseReference(r.URL)
 	if err != nil {
 		return nil, err
 	}
+
+	// Get charts available in the registry
+	var charts []string
+	if ref.Repository != "" {
+		_, err := ociClient.Tags(d.ctx, ref)
+		switch {
+		case err == nil:
+			charts = []string{ref.Repository}
+		case !errors.Is(err, oci.ErrNotFound):
+			return nil, err
+		}
+	}
+	if charts == nil {
+		charts, err = ociClient.Catalog(d.ctx, ref.Registry, ref.Repository)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Add an entry to the index file for each of the charts versions
+	indexFile := repo.NewIndexFile()
+	for _, chartRepository := range charts {
+		chartRef := &oci.Reference{
+			Registry:   ref.Registry,
+			Repository: chartRepository,
+		}
+		tags, err := ociClient.Tags(d.ctx, chartRef)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Base(chartRepository)
+		for _, tag := range tags {
+			digest, err := ociClient.ChartLayerDigest(d.ctx, chartRef, tag)
+			if err != nil {
+				if errors.Is(err, oci.ErrChartLayerNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			chartRef.Digest = digest
+			indexFile.Entries[name] = append(indexFile.Entries[name], &repo.ChartVersion{
+				Metadata: &chart.Metadata{
+					Name:    name,
+					Version: strings.Replace(tag, "_", "+", -1), // Helm replaces + by _ in tags
+				},
+				URLs:   []string{chartRef.String()},
+				Digest: digest,
+			})
+			chartRef.Digest = ""
+		}
+	}
+	indexFile.SortEntries()
+
 	return indexFile, nil
 }
+
+// saveIndexCaches stores the index file cache of the repositories processed
+// without errors, so that they can be skipped the next time they are tracked
+// if their index file has not changed. It must be called once all jobs have
+// been handled.
+func (d *dispatcher) saveIndexCaches() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for chartRepositoryID, cache := range d.indexCaches {
+		if d.ec.hasErrors(chartRepositoryID) {
+			continue
+		}
+		err := d.hubAPI.ChartRepositories.SetIndexCache(d.ctx, chartRepositoryID, cache)
+		if err != nil {
+			log.Error().Err(err).Str("repoID", chartRepositoryID).Msg("Error saving index file cache")
+		}
+	}
+}
+
+// newOCIClient creates a new OCI client for the repository provided, using its
+// credentials when available.
+func newOCIClient(httpClient *http.Client, r *hub.ChartRepository) *oci.Client {
+	var username, password string
+	if r.Credentials != nil {
+		username, password = r.Credentials.Username, r.Credentials.Password
+	}
+	return oci.NewClient(httpClient, username, password)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/artifacthub/hub/internal/api"
 	"github.com/artifacthub/hub/internal/hub"
@@ -17,46 +18,85 @@ const (
 )
 
 // errorsCollector is in charge of collecting errors that happen while chart
-// repositories are being processed. Once all the processing is done, the
-// collected errors can be flushed, which will store them in the database.
+// repositories are being processed, as well as some stats about the chart
+// versions processed. Once all the processing is done, the collected results
+// can be flushed, which will store them in the database as a tracking run.
 type errorsCollector struct {
-	ctx    context.Context
-	hubAPI *api.API
+	ctx       context.Context
+	hubAPI    *api.API
+	startedAt time.Time
 
-	mu     sync.Mutex
-	errors map[string][]error // K: chart repository id
+	mu   sync.Mutex
+	runs map[string]*hub.TrackingRun // K: chart repository id
 }
 
 // newErrorsCollector creates a new errorsCollector instance.
 func newErrorsCollector(ctx context.Context, hubAPI *api.API, repos []*hub.ChartRepository) *errorsCollector {
 	ec := &errorsCollector{
-		ctx:    ctx,
-		hubAPI: hubAPI,
-		errors: make(map[string][]error),
+		ctx:       ctx,
+		hubAPI:    hubAPI,
+		startedAt: time.Now(),
+		runs:      make(map[string]*hub.TrackingRun),
 	}
 	for _, r := range repos {
-		ec.errors[r.ChartRepositoryID] = nil
+		ec.runs[r.ChartRepositoryID] = &hub.TrackingRun{
+			ChartRepositoryID: r.ChartRepositoryID,
+		}
 	}
 	return ec
 }
 
 // appends adds the error provided to the chart repository's list of errors.
-func (c *errorsCollector) append(chartRepositoryID string, err error) {
+func (c *errorsCollector) append(chartRepositoryID string, e *hub.TrackingError) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if len(c.errors[chartRepositoryID]) < maxErrorsPerChartRepository {
-		c.errors[chartRepositoryID] = append(c.errors[chartRepositoryID], err)
+	run := c.getRun(chartRepositoryID)
+	if len(run.Errors) < maxErrorsPerChartRepository {
+		run.Errors = append(run.Errors, e)
 	}
 }
 
-// flush aggregates all errors collected per chart repository as a single text
-// and stores it in the database.
+// countChartVersion updates the stats of the chart repository provided once
+// one of its chart versions has been processed.
+func (c *errorsCollector) countChartVersion(chartRepositoryID string, registered bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	run := c.getRun(chartRepositoryID)
+	run.Processed++
+	if registered {
+		run.Registered++
+	} else {
+		run.Failed++
+	}
+}
+
+// hasErrors checks if any errors have been collected for the chart repository
+// provided.
+func (c *errorsCollector) hasErrors(chartRepositoryID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.getRun(chartRepositoryID).Errors) > 0
+}
+
+// flush registers a tracking run in the database for each of the chart
+// repositories processed with the results collected. The errors collected
+// are also aggregated per chart repository as a single text, which is stored
+// as the last tracking errors of the repository.
 func (c *errorsCollector) flush() {
-	for chartRepositoryID, errors := range c.errors {
+	finishedAt := time.Now()
+	for chartRepositoryID, run := range c.runs {
+		run.StartedAt = c.startedAt.Unix()
+		run.FinishedAt = finishedAt.Unix()
+		if err := c.hubAPI.ChartRepositories.RegisterTrackingRun(c.ctx, run); err != nil {
+			log.Error().Err(err).Str("repoID", chartRepositoryID).Msg("Error registering tracking run")
+		}
+
 		var errStr strings.Builder
-		for _, err := range errors {
-			errStr.WriteString(err.Error())
+		for _, e := range run.Errors {
+			errStr.WriteString(e.Message)
 			errStr.WriteString("\n")
 		}
 		err := c.hubAPI.ChartRepositories.SetLastTrackingResults(c.ctx, chartRepositoryID, errStr.String())
@@ -65,3 +105,16 @@ func (c *errorsCollector) flush() {
 		}
 	}
 }
+
+// getRun returns the tracking run of the chart repository provided, creating
+// it if needed. The caller must hold the collector's lock.
+func (c *errorsCollector) getRun(chartRepositoryID string) *hub.TrackingRun {
+	run, ok := c.runs[chartRepositoryID]
+	if !ok {
+		run = &hub.TrackingRun{
+			ChartRepositoryID: chartRepositoryID,
+		}
+		c.runs[chartRepositoryID] = run
+	}
+	return run
+}
@@ -2,18 +2,27 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 
 	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive"
+	"github.com/artifacthub/hub/internal/encryption"
+	"github.com/artifacthub/hub/internal/helm"
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/img"
 	"github.com/artifacthub/hub/internal/util"
 	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
 )
 
 func main() {
+	// Run as a charts render process when started by a renderer
+	helm.HandleRenderProcess()
+
 	// Setup configuration and logger
 	cfg, err := util.SetupConfig("chart-tracker")
 	if err != nil {
@@ -37,49 +46,116 @@ func main() {
 		log.Info().Msg("Chart tracker shutting down..")
 	}()
 
-	// Setup hub api and image store instances
+	// Setup hub api, image store and archive store instances
 	db, err := util.SetupDB(cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Database setup failed")
 	}
-	hubAPI := api.New(db, nil)
+	var enc hub.Encrypter
+	e, err := encryption.NewEncrypter(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Encrypter setup failed")
+	}
+	if e != nil {
+		enc = e
+	}
+	hubAPI := api.New(db, nil, enc)
 	imageStore, err := util.SetupImageStore(cfg, db)
 	if err != nil {
 		log.Fatal().Err(err).Msg("ImageStore setup failed")
 	}
+	archiveStore, err := util.SetupArchiveStore(cfg, db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("ArchiveStore setup failed")
+	}
 
-	// Get chart repositories to process
-	reposNames := cfg.GetStringSlice("tracker.repositoriesNames")
-	var repos []*hub.ChartRepository
-	if len(reposNames) > 0 {
-		for _, name := range reposNames {
-			repo, err := hubAPI.ChartRepositories.GetByName(context.Background(), name)
-			if err != nil {
-				log.Error().Err(err).Str("name", name).Msg("Error getting chart repository")
-				continue
-			}
-			repos = append(repos, repo)
-		}
+	// Track chart repositories once or continuously when running in daemon
+	// mode, tracking the repositories whose next tracking is due
+	if cfg.GetBool("tracker.daemon") {
+		log.Info().Msg("Chart tracker running in daemon mode")
+		s := newScheduler(ctx, cfg, hubAPI, imageStore, archiveStore)
+		s.run()
 	} else {
-		var err error
-		repos, err = hubAPI.ChartRepositories.GetAll(context.Background())
+		repos, err := getChartRepositories(ctx, cfg, hubAPI)
 		if err != nil {
 			log.Fatal().Err(err).Msg("Error getting chart repositories")
 		}
+		trackChartRepositories(ctx, cfg, hubAPI, imageStore, archiveStore, repos)
+	}
+	log.Info().Msg("Chart tracker finished")
+}
+
+// getChartRepositories returns the chart repositories to process. When some
+// repositories names are provided in the configuration, only those will be
+// returned.
+func getChartRepositories(ctx context.Context, cfg *viper.Viper, hubAPI *api.API) ([]*hub.ChartRepository, error) {
+	reposNames := cfg.GetStringSlice("tracker.repositoriesNames")
+	if len(reposNames) == 0 {
+		return hubAPI.ChartRepositories.GetAll(ctx)
+	}
+	var repos []*hub.ChartRepository
+	for _, name := range reposNames {
+		repo, err := hubAPI.ChartRepositories.GetByName(ctx, name)
+		if err != nil {
+			log.Error().Err(err).Str("name", name).Msg("Error getting chart repository")
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// trackChartRepositories launches the dispatcher and the workers in charge of
+// tracking the repositories provided and waits for them to finish. The errors
+// collected are stored in the database unless the tracking was interrupted,
+// so that the next tracking of the affected repositories is not postponed.
+// Hosted repositories are skipped, as their charts are registered by the hub
+// as they are uploaded.
+func trackChartRepositories(
+	ctx context.Context,
+	cfg *viper.Viper,
+	hubAPI *api.API,
+	imageStore img.Store,
+	archiveStore archive.Store,
+	repos []*hub.ChartRepository,
+) {
+	trackedRepos := make([]*hub.ChartRepository, 0, len(repos))
+	for _, r := range repos {
+		if !r.Hosted {
+			trackedRepos = append(trackedRepos, r)
+		}
 	}
+	repos = trackedRepos
 
-	// Launch dispatcher and workers and wait for them to finish
 	var wg sync.WaitGroup
 	ec := newErrorsCollector(ctx, hubAPI, repos)
+
+	// Repositories whose credentials could not be decrypted are not tracked,
+	// the error is recorded as a tracking error instead
+	validRepos := make([]*hub.ChartRepository, 0, len(repos))
+	for _, r := range repos {
+		if r.CredentialsError != nil {
+			ec.append(r.ChartRepositoryID, &hub.TrackingError{
+				Kind:    hub.CredentialsTrackingError,
+				Message: fmt.Sprintf("error decrypting credentials of repository %s: %s", r.Name, r.CredentialsError),
+			})
+			continue
+		}
+		validRepos = append(validRepos, r)
+	}
+
 	dispatcher := newDispatcher(ctx, ec, hubAPI)
 	wg.Add(1)
-	go dispatcher.run(&wg, repos)
+	go dispatcher.run(&wg, validRepos)
 	for i := 0; i < cfg.GetInt("tracker.numWorkers"); i++ {
-		w := newWorker(ctx, i, ec, hubAPI, imageStore)
+		w := newWorker(ctx, i, ec, hubAPI, imageStore, archiveStore)
 		wg.Add(1)
 		go w.run(&wg, dispatcher.Queue)
 	}
 	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
 	ec.flush()
-	log.Info().Msg("Chart tracker finished")
+	dispatcher.saveIndexCaches()
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/img"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultPollInterval represents the default interval used by the
+	// scheduler to check if there are chart repositories due for tracking.
+	defaultPollInterval = 1 * time.Minute
+
+	// defaultMaxConcurrentRepositories represents the default maximum number
+	// of chart repositories the scheduler tracks concurrently.
+	defaultMaxConcurrentRepositories = 5
+)
+
+// scheduler is in charge of tracking continuously the chart repositories whose
+// next tracking is due. The next tracking of each repository is scheduled in
+// the database once it has been tracked, based on its tracking interval and
+// jitter. Each repository is tracked independently, so that a slow one does
+// not delay the tracking of the others.
+type scheduler struct {
+	ctx            context.Context
+	cfg            *viper.Viper
+	hubAPI         *api.API
+	imageStore     img.Store
+	archiveStore   archive.Store
+	maxConcurrency int
+
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	inProgress map[string]struct{} // K: chart repository id
+}
+
+// newScheduler creates a new scheduler instance.
+func newScheduler(
+	ctx context.Context,
+	cfg *viper.Viper,
+	hubAPI *api.API,
+	imageStore img.Store,
+	archiveStore archive.Store,
+) *scheduler {
+	maxConcurrency := cfg.GetInt("tracker.maxConcurrentRepositories")
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrentRepositories
+	}
+	return &scheduler{
+		ctx:            ctx,
+		cfg:            cfg,
+		hubAPI:         hubAPI,
+		imageStore:     imageStore,
+		archiveStore:   archiveStore,
+		maxConcurrency: maxConcurrency,
+		inProgress:     make(map[string]struct{}),
+	}
+}
+
+// run starts the scheduler. It will keep tracking the chart repositories due
+// until the context is cancelled. When that happens in the middle of a
+// tracking cycle, the cycle will be interrupted and the repositories involved
+// will be tracked again the next time the scheduler runs.
+func (s *scheduler) run() {
+	pollInterval := s.cfg.GetDuration("tracker.pollInterval")
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	for {
+		repos, err := s.getChartRepositoriesDue()
+		if err != nil {
+			log.Error().Err(err).Msg("Error getting chart repositories due for tracking")
+		} else {
+			for _, r := range repos {
+				s.dispatch(r)
+			}
+		}
+		select {
+		case <-s.ctx.Done():
+			s.wg.Wait()
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// dispatch launches the tracking of the chart repository provided in its own
+// goroutine. Repositories already being tracked are skipped, as well as all
+// of them once the maximum number of repositories tracked concurrently has
+// been reached. As they are still due, they will be dispatched again in the
+// next poll.
+func (s *scheduler) dispatch(r *hub.ChartRepository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inProgress[r.ChartRepositoryID]; ok {
+		return
+	}
+	if len(s.inProgress) >= s.maxConcurrency {
+		return
+	}
+	s.inProgress[r.ChartRepositoryID] = struct{}{}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		log.Info().Str("repo", r.Name).Msg("Tracking chart repository due")
+		trackChartRepositories(s.ctx, s.cfg, s.hubAPI, s.imageStore, s.archiveStore, []*hub.ChartRepository{r})
+		s.mu.Lock()
+		delete(s.inProgress, r.ChartRepositoryID)
+		s.mu.Unlock()
+	}()
+}
+
+// getChartRepositoriesDue returns the chart repositories whose next tracking
+// is due. When some repositories names are provided in the configuration, only
+// those will be considered.
+func (s *scheduler) getChartRepositoriesDue() ([]*hub.ChartRepository, error) {
+	repos, err := s.hubAPI.ChartRepositories.GetDueForTracking(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	reposNames := s.cfg.GetStringSlice("tracker.repositoriesNames")
+	if len(reposNames) == 0 {
+		return repos, nil
+	}
+	var selectedRepos []*hub.ChartRepository
+	for _, r := range repos {
+		for _, name := range reposNames {
+			if r.Name == name {
+				selectedRepos = append(selectedRepos, r)
+				break
+			}
+		}
+	}
+	return selectedRepos, nil
+}
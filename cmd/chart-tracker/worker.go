@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
@@ -14,37 +16,65 @@ import (
 	"time"
 
 	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive"
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/helm"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/img"
+	"github.com/artifacthub/hub/internal/oci"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
+const (
+	// chartRenderTimeout represents the maximum time linting a chart and
+	// rendering its templates can take.
+	chartRenderTimeout = 30 * time.Second
+
+	// maxRenderedSize represents the maximum size of the manifests produced
+	// when rendering a chart.
+	maxRenderedSize = 10 * 1024 * 1024
+)
+
+// errFileNotFound indicates that the file requested was not found.
+var errFileNotFound = errors.New("file not found")
+
 // worker is in charge of handling jobs generated by the dispatcher.
 type worker struct {
-	ctx        context.Context
-	id         int
-	ec         *errorsCollector
-	hubAPI     *api.API
-	imageStore img.Store
-	logger     zerolog.Logger
-	httpClient *http.Client
+	ctx          context.Context
+	id           int
+	ec           *errorsCollector
+	hubAPI       *api.API
+	imageStore   img.Store
+	archiveStore archive.Store
+	logger       zerolog.Logger
+	httpClient   *http.Client
+	renderer     *helm.Renderer
 }
 
 // newWorker creates a new worker instance.
-func newWorker(ctx context.Context, id int, ec *errorsCollector, hubAPI *api.API, imageStore img.Store) *worker {
+func newWorker(
+	ctx context.Context,
+	id int,
+	ec *errorsCollector,
+	hubAPI *api.API,
+	imageStore img.Store,
+	archiveStore archive.Store,
+) *worker {
 	return &worker{
-		ctx:        ctx,
-		id:         id,
-		ec:         ec,
-		hubAPI:     hubAPI,
-		imageStore: imageStore,
-		logger:     log.With().Int("worker", id).Logger(),
+		ctx:          ctx,
+		id:           id,
+		ec:           ec,
+		hubAPI:       hubAPI,
+		imageStore:   imageStore,
+		archiveStore: archiveStore,
+		logger:       log.With().Int("worker", id).Logger(),
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		renderer: helm.NewRenderer(1, chartRenderTimeout, maxRenderedSize),
 	}
 }
 
@@ -81,8 +111,14 @@ func (w *worker) run(wg *sync.WaitGroup, queue chan *job) {
 // handleJob handles the provided job. This involves downloading the chart
 // archive, extracting its contents and register the corresponding package.
 func (w *worker) handleJob(j *job) error {
+	var registered bool
 	defer func() {
 		if r := recover(); r != nil {
+			w.appendError(j, hub.InternalTrackingError, "", fmt.Errorf(
+				"unexpected error handling chart %s version %s",
+				j.chartVersion.Metadata.Name,
+				j.chartVersion.Metadata.Version,
+			))
 			w.logger.Error().
 				Str("repo", j.repo.Name).
 				Str("chart", j.chartVersion.Metadata.Name).
@@ -91,6 +127,7 @@ func (w *worker) handleJob(j *job) error {
 				Interface("recorver", r).
 				Msg("handleJob panic")
 		}
+		w.ec.countChartVersion(j.repo.ChartRepositoryID, registered)
 	}()
 
 	// Prepare chart archive url
@@ -98,7 +135,7 @@ func (w *worker) handleJob(j *job) error {
 	if _, err := url.ParseRequestURI(u); err != nil {
 		tmp, err := url.Parse(j.repo.URL)
 		if err != nil {
-			w.ec.append(j.repo.ChartRepositoryID, fmt.Errorf("invalid chart url: %s", u))
+			w.appendError(j, hub.InvalidChartTrackingError, u, fmt.Errorf("invalid chart url: %s", u))
 			w.logger.Error().Str("url", u).Msg("invalid url")
 			return err
 		}
@@ -107,9 +144,9 @@ func (w *worker) handleJob(j *job) error {
 	}
 
 	// Load chart from remote archive
-	chart, err := w.loadChart(u)
+	chart, archive, err := w.loadChart(j, u)
 	if err != nil {
-		w.ec.append(j.repo.ChartRepositoryID, fmt.Errorf("error loading chart %s: %w", u, err))
+		w.appendError(j, hub.ChartLoadTrackingError, u, fmt.Errorf("error loading chart %s: %w", u, err))
 		w.logger.Warn().
 			Str("repo", j.repo.Name).
 			Str("chart", j.chartVersion.Metadata.Name).
@@ -127,7 +164,7 @@ func (w *worker) handleJob(j *job) error {
 			logoURL = md.Icon
 			data, err := w.downloadImage(md.Icon)
 			if err != nil {
-				w.ec.append(j.repo.ChartRepositoryID, fmt.Errorf("error dowloading logo %s: %w", md.Icon, err))
+				w.appendError(j, hub.LogoTrackingError, md.Icon, fmt.Errorf("error dowloading logo %s: %w", md.Icon, err))
 				w.logger.Debug().Err(err).Str("url", md.Icon).Msg("Image download failed")
 			} else {
 				logoImageID, err = w.imageStore.SaveImage(w.ctx, data)
@@ -138,64 +175,160 @@ func (w *worker) handleJob(j *job) error {
 		}
 	}
 
-	// Prepare hub package to be registered
-	p := &hub.Package{
-		Kind:            hub.Chart,
-		Name:            md.Name,
-		Description:     md.Description,
-		HomeURL:         md.Home,
-		LogoURL:         logoURL,
-		LogoImageID:     logoImageID,
-		Keywords:        md.Keywords,
-		Deprecated:      md.Deprecated,
-		Version:         md.Version,
-		AppVersion:      md.AppVersion,
-		Digest:          j.chartVersion.Digest,
-		ChartRepository: j.repo,
-	}
-	readme := getFile(chart, "README.md")
-	if readme != nil {
-		p.Readme = string(readme.Data)
-	}
-	var maintainers []*hub.Maintainer
-	for _, entry := range md.Maintainers {
-		if entry.Email != "" {
-			maintainers = append(maintainers, &hub.Maintainer{
-				Name:  entry.Name,
-				Email: entry.Email,
-			})
+	// Prepare hub package to be registered (each worker has its own
+	// renderer, so it is never busy)
+	p, errs, err := helm.NewPackage(chart, u, w.renderer)
+	if err != nil {
+		return err
+	}
+	for _, e := range errs {
+		w.ec.append(j.repo.ChartRepositoryID, e)
+	}
+	p.LogoURL = logoURL
+	p.LogoImageID = logoImageID
+	p.Digest = j.chartVersion.Digest
+	p.ChartRepository = &hub.ChartRepository{
+		ChartRepositoryID: j.repo.ChartRepositoryID,
+	}
+
+	// Store chart archive in the mirror when enabled
+	if w.archiveStore != nil {
+		digest, err := w.archiveStore.SaveArchive(w.ctx, archive)
+		if err != nil {
+			w.appendError(j, hub.MirrorTrackingError, u, fmt.Errorf(
+				"error mirroring archive of chart %s version %s: %w", md.Name, md.Version, err,
+			))
+		} else {
+			p.ArchiveDigest = digest
+			p.ChartMetadata, _ = json.Marshal(md)
 		}
 	}
-	if len(maintainers) > 0 {
-		p.Maintainers = maintainers
+
+	// Verify chart provenance file when available
+	if !oci.IsOCI(u) {
+		signed, signer, err := w.checkProvenance(j, u, archive)
+		if err != nil {
+			w.appendError(
+				j,
+				hub.ProvenanceTrackingError,
+				u+".prov",
+				fmt.Errorf("error verifying provenance file of chart %s version %s: %w", md.Name, md.Version, err),
+			)
+		}
+		p.Signed = signed
+		p.SignatureVerified = signer != ""
+		p.Signer = signer
 	}
 
 	// Register package
-	err = w.hubAPI.Packages.Register(w.ctx, p)
-	if err != nil {
-		w.ec.append(
-			j.repo.ChartRepositoryID,
+	if err := w.hubAPI.Packages.Register(w.ctx, p); err != nil {
+		w.appendError(
+			j,
+			hub.RegisterTrackingError,
+			u,
 			fmt.Errorf("error registering package %s version %s: %w", p.Name, p.Version, err),
 		)
+		return err
 	}
-	return err
+	registered = true
+	return nil
 }
 
-// loadChart loads a chart from a remote archive located at the url provided.
-func (w *worker) loadChart(u string) (*chart.Chart, error) {
-	resp, err := w.httpClient.Get(u)
+// appendError adds an error of the kind provided, related to the job's chart
+// version, to the errors collector.
+func (w *worker) appendError(j *job, kind hub.TrackingErrorKind, u string, err error) {
+	md := j.chartVersion.Metadata
+	w.ec.append(j.repo.ChartRepositoryID, &hub.TrackingError{
+		Kind:         kind,
+		ChartName:    md.Name,
+		ChartVersion: md.Version,
+		URL:          u,
+		Message:      err.Error(),
+	})
+}
+
+// loadChart loads a chart from a remote archive located at the url provided,
+// using the job's repository http client. The raw content of the archive is
+// returned as well.
+func (w *worker) loadChart(j *job, u string) (*chart.Chart, []byte, error) {
+	var archive []byte
+	var err error
+	if oci.IsOCI(u) {
+		archive, err = w.pullOCIChart(newOCIClient(j.httpClient, j.repo), u)
+	} else {
+		archive, err = w.downloadFile(j.httpClient, u)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	chart, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, nil, err
+	}
+	return chart, archive, nil
+}
+
+// pullOCIChart pulls the chart archive from the OCI registry blob referenced
+// by the url provided.
+func (w *worker) pullOCIChart(ociClient *oci.Client, u string) ([]byte, error) {
+	ref, err := oci.ParseReference(u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusOK {
-		chart, err := loader.LoadArchive(resp.Body)
-		if err != nil {
-			return nil, err
+	blob, err := ociClient.PullBlob(w.ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Close()
+	return ioutil.ReadAll(blob)
+}
+
+// checkProvenance downloads the provenance file of the chart archive located
+// at the url provided, when available, and verifies it using the job's
+// keyring. It returns whether the chart is signed and, if its signature could
+// be verified, the identity of the signer.
+func (w *worker) checkProvenance(j *job, u string, archive []byte) (bool, string, error) {
+	prov, err := w.downloadFile(j.httpClient, u+".prov")
+	if err != nil {
+		if errors.Is(err, errFileNotFound) {
+			return false, "", nil
 		}
-		return chart, nil
+		return false, "", err
+	}
+	if len(j.keyring) == 0 {
+		return true, "", nil
+	}
+	tmp, err := url.Parse(u)
+	if err != nil {
+		return true, "", err
+	}
+	signer, err := chartrepo.VerifyProvenance(j.keyring, archive, path.Base(tmp.Path), prov)
+	if err != nil {
+		return true, "", err
+	}
+	return true, signer, nil
+}
+
+// downloadFile downloads the file located at the url provided using the http
+// client given. When the file is not found, errFileNotFound is returned.
+func (w *worker) downloadFile(httpClient *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req.WithContext(w.ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errFileNotFound
+	default:
+		return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
 	}
-	return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
 }
 
 // downloadImage downloads the image located at the url provided.
@@ -210,13 +343,3 @@ func (w *worker) downloadImage(u string) ([]byte, error) {
 	}
 	return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
 }
-
-// getFile returns the file requested from the provided chart.
-func getFile(chart *chart.Chart, name string) *chart.File {
-	for _, file := range chart.Files {
-		if file.Name == name {
-			return file
-		}
-	}
-	return nil
-}
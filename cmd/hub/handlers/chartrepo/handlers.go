@@ -2,13 +2,16 @@ package chartrepo
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"regexp"
 
 	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
 	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/chartrepo"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -41,6 +44,10 @@ func (h *Handlers) Add(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "chart repository provided is not valid", http.StatusBadRequest)
 		return
 	}
+	if repo.Hosted {
+		// Hosted chart repositories are served by the hub itself
+		repo.URL = helpers.GetBaseURL(r) + "/api/v1/hosted/" + repo.Name
+	}
 	if repo.Name == "" || repo.URL == "" {
 		http.Error(w, "chart repository name and url must be provided", http.StatusBadRequest)
 		return
@@ -49,6 +56,16 @@ func (h *Handlers) Add(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid chart repository name", http.StatusBadRequest)
 		return
 	}
+	if repo.TrackingInterval < 0 || repo.TrackingJitter < 0 {
+		http.Error(w, "invalid chart repository tracking schedule", http.StatusBadRequest)
+		return
+	}
+	if repo.Keyring != nil && *repo.Keyring != "" {
+		if _, err := chartrepo.ParseKeyring(*repo.Keyring); err != nil {
+			http.Error(w, "invalid chart repository keyring", http.StatusBadRequest)
+			return
+		}
+	}
 	if err := h.hubAPI.ChartRepositories.Add(r.Context(), orgName, repo); err != nil {
 		h.logger.Error().Err(err).Str("method", "Add").Send()
 		http.Error(w, "", http.StatusInternalServerError)
@@ -67,6 +84,49 @@ func (h *Handlers) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DeleteWebhookSecret is an http handler that deletes the webhook secret of
+// the provided chart repository, disabling its webhook.
+func (h *Handlers) DeleteWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	if err := h.hubAPI.ChartRepositories.DeleteWebhookSecret(r.Context(), repoName); err != nil {
+		h.logger.Error().Err(err).Str("method", "DeleteWebhookSecret").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GenerateWebhookSecret is an http handler that generates a new webhook secret
+// for the provided chart repository. The secret is returned along with the
+// webhook url that should be configured in the service hosting the chart
+// repository index file.
+func (h *Handlers) GenerateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	jsonData, err := h.hubAPI.ChartRepositories.GenerateWebhookSecret(r.Context(), repoName)
+	if errors.Is(err, chartrepo.ErrHostedChartRepository) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GenerateWebhookSecret").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	var webhook struct {
+		ChartRepositoryID string `json:"chart_repository_id"`
+		Secret            string `json:"secret"`
+	}
+	if err := json.Unmarshal(jsonData, &webhook); err != nil {
+		h.logger.Error().Err(err).Str("method", "GenerateWebhookSecret").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	jsonData, _ = json.Marshal(map[string]string{
+		"url":    helpers.GetBaseURL(r) + "/api/v1/webhook/chart-repository/" + webhook.ChartRepositoryID,
+		"secret": webhook.Secret,
+	})
+	helpers.RenderJSON(w, jsonData, 0)
+}
+
 // GetOwnedByOrg is an http handler that returns the chart repositories owned
 // by the organization provided. The user doing the request must belong to the
 // organization.
@@ -93,6 +153,73 @@ func (h *Handlers) GetOwnedByUser(w http.ResponseWriter, r *http.Request) {
 	helpers.RenderJSON(w, jsonData, 0)
 }
 
+// GetTrackingRequest is an http handler that returns the tracking request
+// provided of the given chart repository. The user doing the request must own
+// the chart repository or belong to the organization which owns it.
+func (h *Handlers) GetTrackingRequest(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	trackingRequestID := chi.URLParam(r, "trackingRequestID")
+	jsonData, err := h.hubAPI.ChartRepositories.GetTrackingRequestJSON(r.Context(), repoName, trackingRequestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Str("method", "GetTrackingRequest").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, jsonData, 0)
+}
+
+// GetTrackingRunErrors is an http handler that returns the errors found during
+// the tracking run provided of the given chart repository. The user doing the
+// request must own the chart repository or belong to the organization which
+// owns it.
+func (h *Handlers) GetTrackingRunErrors(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	trackingRunID := chi.URLParam(r, "trackingRunID")
+	jsonData, err := h.hubAPI.ChartRepositories.GetTrackingRunErrorsJSON(r.Context(), repoName, trackingRunID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetTrackingRunErrors").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	helpers.RenderJSON(w, jsonData, 0)
+}
+
+// GetTrackingRuns is an http handler that returns the most recent tracking
+// runs of the chart repository provided. The user doing the request must own
+// the chart repository or belong to the organization which owns it.
+func (h *Handlers) GetTrackingRuns(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	jsonData, err := h.hubAPI.ChartRepositories.GetTrackingRunsJSON(r.Context(), repoName)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetTrackingRuns").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	helpers.RenderJSON(w, jsonData, 0)
+}
+
+// RequestTracking is an http handler that requests the tracking of the chart
+// repository provided as soon as possible, returning the tracking request. Its
+// status can be checked later using the GetTrackingRequest handler.
+func (h *Handlers) RequestTracking(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	jsonData, err := h.hubAPI.ChartRepositories.RequestTracking(r.Context(), repoName)
+	if errors.Is(err, chartrepo.ErrHostedChartRepository) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "RequestTracking").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	helpers.RenderJSON(w, jsonData, 0)
+}
+
 // Update is an http handler that updates the provided chart repository in the
 // database.
 func (h *Handlers) Update(w http.ResponseWriter, r *http.Request) {
@@ -103,6 +230,16 @@ func (h *Handlers) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	repo.Name = chi.URLParam(r, "repoName")
+	if repo.TrackingInterval < 0 || repo.TrackingJitter < 0 {
+		http.Error(w, "invalid chart repository tracking schedule", http.StatusBadRequest)
+		return
+	}
+	if repo.Keyring != nil && *repo.Keyring != "" {
+		if _, err := chartrepo.ParseKeyring(*repo.Keyring); err != nil {
+			http.Error(w, "invalid chart repository keyring", http.StatusBadRequest)
+			return
+		}
+	}
 	if err := h.hubAPI.ChartRepositories.Update(r.Context(), repo); err != nil {
 		log.Error().Err(err).Str("method", "Update").Send()
 		http.Error(w, "", http.StatusInternalServerError)
@@ -2,6 +2,7 @@ package chartrepo
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -12,9 +13,12 @@ import (
 	"github.com/artifacthub/hub/internal/api"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -58,6 +62,18 @@ func TestAdd(t *testing.T) {
 				"invalid name",
 				`{"name": "REPO_UPPERCASE"}`,
 			},
+			{
+				"invalid tracking interval",
+				`{"name": "repo1", "url": "https://repo1.url", "tracking_interval": -1}`,
+			},
+			{
+				"invalid tracking jitter",
+				`{"name": "repo1", "url": "https://repo1.url", "tracking_jitter": -1}`,
+			},
+			{
+				"invalid keyring",
+				`{"name": "repo1", "url": "https://repo1.url", "keyring": "invalid"}`,
+			},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -119,6 +135,26 @@ func TestAdd(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("hosted chart repository provided", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, "userID", mock.Anything, mock.MatchedBy(func(rJSON []byte) bool {
+			var repo *hub.ChartRepository
+			_ = json.Unmarshal(rJSON, &repo)
+			return repo.Hosted && repo.URL == "http://hub.url/api/v1/hosted/repo1"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		repoJSON := `{"name": "repo1", "url": "https://repo1.url", "hosted": true}`
+		r, _ := http.NewRequest("POST", "http://hub.url/", strings.NewReader(repoJSON))
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.Add(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
 }
 
 func TestDelete(t *testing.T) {
@@ -155,6 +191,104 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestDeleteWebhookSecret(t *testing.T) {
+	dbQuery := "select delete_chart_repository_webhook_secret($1::uuid, $2::text)"
+
+	t.Run("valid request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, mock.Anything, mock.Anything).Return(nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.DeleteWebhookSecret(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, mock.Anything, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.DeleteWebhookSecret(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	dbQuery := "select set_chart_repository_webhook_secret($1::uuid, $2::text, $3::text)"
+
+	t.Run("valid request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, "ZW5jcnlwdGVk").Return("repoID", nil)
+		hw.enc.On("Encrypt", mock.Anything).Return([]byte("encrypted"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r.Host = "localhost:8000"
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GenerateWebhookSecret(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		var webhook map[string]string
+		require.NoError(t, json.Unmarshal(data, &webhook))
+		assert.Equal(t, "http://localhost:8000/api/v1/webhook/chart-repository/repoID", webhook["url"])
+		assert.Len(t, webhook["secret"], 64)
+		hw.db.AssertExpectations(t)
+		hw.enc.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, &pgconn.PgError{Code: "55000"})
+		hw.enc.On("Encrypt", mock.Anything).Return([]byte("encrypted"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GenerateWebhookSecret(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+		hw.enc.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		hw.enc.On("Encrypt", mock.Anything).Return([]byte("encrypted"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GenerateWebhookSecret(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
 func TestGetOwnedByOrg(t *testing.T) {
 	dbQuery := "select get_org_chart_repositories($1::uuid, $2::text)"
 
@@ -233,6 +367,192 @@ func TestGetOwnedByUser(t *testing.T) {
 	})
 }
 
+func TestGetTrackingRequest(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_request($1::uuid, $2::text, $3::uuid)"
+
+	t.Run("non existing tracking request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRequest(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRequest(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRequest(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestGetTrackingRunErrors(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_run_errors($1::uuid, $2::text, $3::uuid)"
+
+	t.Run("valid request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRunErrors(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRunErrors(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestGetTrackingRuns(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_runs($1::uuid, $2::text)"
+
+	t.Run("valid request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRuns(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.GetTrackingRuns(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestRequestTracking(t *testing.T) {
+	dbQuery := "select request_chart_repository_tracking($1::uuid, $2::text)"
+
+	t.Run("valid request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.RequestTracking(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything).Return(nil, &pgconn.PgError{Code: "55000"})
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.RequestTracking(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		hw.h.RequestTracking(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	dbQuery := "select update_chart_repository($1::uuid, $2::jsonb)"
 
@@ -249,6 +569,14 @@ func TestUpdate(t *testing.T) {
 				"invalid json",
 				"-",
 			},
+			{
+				"invalid tracking interval",
+				`{"url": "https://repo1.url", "tracking_interval": -1}`,
+			},
+			{
+				"invalid keyring",
+				`{"url": "https://repo1.url", "keyring": "invalid"}`,
+			},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -311,16 +639,19 @@ func TestUpdate(t *testing.T) {
 }
 
 type handlersWrapper struct {
-	db *tests.DBMock
-	h  *Handlers
+	db  *tests.DBMock
+	enc *tests.EncrypterMock
+	h   *Handlers
 }
 
 func newHandlersWrapper() *handlersWrapper {
 	db := &tests.DBMock{}
-	hubAPI := api.New(db, nil)
+	enc := &tests.EncrypterMock{}
+	hubAPI := api.New(db, nil, enc)
 
 	return &handlersWrapper{
-		db: db,
-		h:  NewHandlers(hubAPI),
+		db:  db,
+		enc: enc,
+		h:   NewHandlers(hubAPI),
 	}
 }
@@ -0,0 +1,132 @@
+package chartrepo
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec, required to verify legacy GitHub signatures
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// maxWebhookPayloadSize represents the maximum size of the webhook
+	// payloads accepted.
+	maxWebhookPayloadSize = 1 << 20
+)
+
+// uuidRE is a regexp used to validate a chart repository id.
+var uuidRE = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Webhook is an http handler that receives push events from the service
+// hosting a chart repository index file (GitHub, GitLab or any other service
+// able to sign its payloads using HMAC-SHA256). Once the request has been
+// verified using the chart repository webhook secret, a tracking request is
+// registered so that the chart repository is processed as soon as possible.
+// Hosted chart repositories are not tracked, so they do not have a webhook.
+func (h *Handlers) Webhook(w http.ResponseWriter, r *http.Request) {
+	chartRepositoryID := chi.URLParam(r, "chartRepositoryID")
+	if !uuidRE.MatchString(chartRepositoryID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Get chart repository webhook secret
+	secret, err := h.hubAPI.ChartRepositories.GetWebhookSecret(r.Context(), chartRepositoryID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		h.logger.Error().Err(err).Str("method", "Webhook").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if secret == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Read payload and verify request
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize+1))
+	if err != nil {
+		http.Error(w, "error reading payload", http.StatusBadRequest)
+		return
+	}
+	if len(payload) > maxWebhookPayloadSize {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !verifyWebhookRequest(r, payload, secret) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	// Register tracking request when a push event is received
+	if !isPushEvent(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	err = h.hubAPI.ChartRepositories.AddTrackingRequest(r.Context(), chartRepositoryID)
+	if errors.Is(err, chartrepo.ErrHostedChartRepository) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "Webhook").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verifyWebhookRequest checks if the webhook request provided has been signed
+// (or includes the token, in the case of GitLab) using the secret provided.
+func verifyWebhookRequest(r *http.Request, payload []byte, secret string) bool {
+	switch {
+	case r.Header.Get("X-Hub-Signature-256") != "":
+		return verifySignature(r.Header.Get("X-Hub-Signature-256"), "sha256=", sha256.New, payload, secret)
+	case r.Header.Get("X-Hub-Signature") != "":
+		return verifySignature(r.Header.Get("X-Hub-Signature"), "sha1=", sha1.New, payload, secret)
+	case r.Header.Get("X-Gitlab-Token") != "":
+		return subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret)) == 1
+	case r.Header.Get("X-Signature-256") != "":
+		return verifySignature(r.Header.Get("X-Signature-256"), "sha256=", sha256.New, payload, secret)
+	}
+	return false
+}
+
+// verifySignature checks if the signature provided, which is expected to be
+// the hex encoded HMAC of the payload using the secret given, preceded by the
+// prefix provided, is valid.
+func verifySignature(signature, prefix string, h func() hash.Hash, payload []byte, secret string) bool {
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, []byte(secret))
+	_, _ = mac.Write(payload)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// isPushEvent checks if the webhook request provided corresponds to a push
+// event. Requests that do not identify the kind of event they carry are
+// considered push events.
+func isPushEvent(r *http.Request) bool {
+	if event := r.Header.Get("X-GitHub-Event"); event != "" {
+		return event == "push"
+	}
+	if event := r.Header.Get("X-Gitlab-Event"); event != "" {
+		return event == "Push Hook"
+	}
+	return true
+}
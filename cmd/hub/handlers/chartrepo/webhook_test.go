@@ -0,0 +1,274 @@
+package chartrepo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	getSecretDBQuery := `
+	select coalesce(webhook_secret, '')
+	from chart_repository
+	where chart_repository_id = $1::uuid
+	and hosted = false`
+	addTrackingRequestDBQuery := "select add_tracking_request($1::uuid)"
+	repoID := "00000000-0000-0000-0000-000000000001"
+	payload := `{"ref": "refs/heads/gh-pages"}`
+
+	newRequest := func(id string, headers map[string]string) *http.Request {
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(payload))
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		rctx := &chi.Context{
+			URLParams: chi.RouteParams{
+				Keys:   []string{"chartRepositoryID"},
+				Values: []string{id},
+			},
+		}
+		return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	t.Run("invalid chart repository id", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		hw.h.Webhook(w, newRequest("invalid", nil))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart repository not found", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSecretDBQuery, repoID).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		hw.h.Webhook(w, newRequest(repoID, nil))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart repository webhook not enabled", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSecretDBQuery, repoID).Return("", nil)
+
+		w := httptest.NewRecorder()
+		hw.h.Webhook(w, newRequest(repoID, nil))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("error getting webhook secret", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSecretDBQuery, repoID).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		hw.h.Webhook(w, newRequest(repoID, nil))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			headers     map[string]string
+		}{
+			{
+				"no signature",
+				nil,
+			},
+			{
+				"github signature using a different secret",
+				map[string]string{"X-Hub-Signature-256": "sha256=" + sign(sha256.New, "other", payload)},
+			},
+			{
+				"github signature without prefix",
+				map[string]string{"X-Hub-Signature-256": sign(sha256.New, "secret", payload)},
+			},
+			{
+				"github signature not hex encoded",
+				map[string]string{"X-Hub-Signature-256": "sha256=invalid"},
+			},
+			{
+				"github legacy signature using a different secret",
+				map[string]string{"X-Hub-Signature": "sha1=" + sign(sha1.New, "other", payload)},
+			},
+			{
+				"invalid gitlab token",
+				map[string]string{"X-Gitlab-Token": "other"},
+			},
+			{
+				"generic signature using a different secret",
+				map[string]string{"X-Signature-256": "sha256=" + sign(sha256.New, "other", payload)},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.description, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getSecretDBQuery, repoID).Return("ZW5jcnlwdGVk", nil)
+				hw.enc.On("Decrypt", []byte("encrypted")).Return([]byte("secret"), nil)
+
+				w := httptest.NewRecorder()
+				hw.h.Webhook(w, newRequest(repoID, tc.headers))
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("events other than push are ignored", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			headers     map[string]string
+		}{
+			{
+				"github ping event",
+				map[string]string{
+					"X-GitHub-Event":      "ping",
+					"X-Hub-Signature-256": "sha256=" + sign(sha256.New, "secret", payload),
+				},
+			},
+			{
+				"gitlab tag push event",
+				map[string]string{
+					"X-Gitlab-Event": "Tag Push Hook",
+					"X-Gitlab-Token": "secret",
+				},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.description, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getSecretDBQuery, repoID).Return("ZW5jcnlwdGVk", nil)
+				hw.enc.On("Decrypt", []byte("encrypted")).Return([]byte("secret"), nil)
+
+				w := httptest.NewRecorder()
+				hw.h.Webhook(w, newRequest(repoID, tc.headers))
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("valid push events", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			headers     map[string]string
+		}{
+			{
+				"github push event",
+				map[string]string{
+					"X-GitHub-Event":      "push",
+					"X-Hub-Signature-256": "sha256=" + sign(sha256.New, "secret", payload),
+				},
+			},
+			{
+				"github push event with legacy signature",
+				map[string]string{
+					"X-GitHub-Event":  "push",
+					"X-Hub-Signature": "sha1=" + sign(sha1.New, "secret", payload),
+				},
+			},
+			{
+				"gitlab push event",
+				map[string]string{
+					"X-Gitlab-Event": "Push Hook",
+					"X-Gitlab-Token": "secret",
+				},
+			},
+			{
+				"generic push event",
+				map[string]string{
+					"X-Signature-256": "sha256=" + sign(sha256.New, "secret", payload),
+				},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.description, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getSecretDBQuery, repoID).Return("ZW5jcnlwdGVk", nil)
+				hw.enc.On("Decrypt", []byte("encrypted")).Return([]byte("secret"), nil)
+				hw.db.On("Exec", addTrackingRequestDBQuery, repoID).Return(nil)
+
+				w := httptest.NewRecorder()
+				hw.h.Webhook(w, newRequest(repoID, tc.headers))
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("hosted chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSecretDBQuery, repoID).Return("ZW5jcnlwdGVk", nil)
+		hw.enc.On("Decrypt", []byte("encrypted")).Return([]byte("secret"), nil)
+		hw.db.On("Exec", addTrackingRequestDBQuery, repoID).Return(&pgconn.PgError{Code: "55000"})
+
+		w := httptest.NewRecorder()
+		hw.h.Webhook(w, newRequest(repoID, map[string]string{"X-Gitlab-Token": "secret"}))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("error adding tracking request", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSecretDBQuery, repoID).Return("ZW5jcnlwdGVk", nil)
+		hw.enc.On("Decrypt", []byte("encrypted")).Return([]byte("secret"), nil)
+		hw.db.On("Exec", addTrackingRequestDBQuery, repoID).Return(tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		hw.h.Webhook(w, newRequest(repoID, map[string]string{"X-Gitlab-Token": "secret"}))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func sign(h func() hash.Hash, secret, payload string) string {
+	mac := hmac.New(h, []byte(secret))
+	_, _ = mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
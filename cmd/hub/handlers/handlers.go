@@ -5,11 +5,14 @@ import (
 	"path"
 
 	"github.com/artifacthub/hub/cmd/hub/handlers/chartrepo"
+	"github.com/artifacthub/hub/cmd/hub/handlers/hosted"
+	"github.com/artifacthub/hub/cmd/hub/handlers/mirror"
 	"github.com/artifacthub/hub/cmd/hub/handlers/org"
 	"github.com/artifacthub/hub/cmd/hub/handlers/pkg"
 	"github.com/artifacthub/hub/cmd/hub/handlers/static"
 	"github.com/artifacthub/hub/cmd/hub/handlers/user"
 	"github.com/artifacthub/hub/internal/api"
+	archivepg "github.com/artifacthub/hub/internal/archive/pg"
 	"github.com/artifacthub/hub/internal/img/pg"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -31,11 +34,18 @@ type Handlers struct {
 	User              *user.Handlers
 	Packages          *pkg.Handlers
 	ChartRepositories *chartrepo.Handlers
+	Hosted            *hosted.Handlers
+	Mirror            *mirror.Handlers
 	Static            *static.Handlers
 }
 
 // Setup creates a new Handlers instance.
-func Setup(cfg *viper.Viper, hubAPI *api.API, imageStore *pg.ImageStore) *Handlers {
+func Setup(
+	cfg *viper.Viper,
+	hubAPI *api.API,
+	imageStore *pg.ImageStore,
+	archiveStore *archivepg.ArchiveStore,
+) *Handlers {
 	h := &Handlers{
 		cfg:    cfg,
 		hubAPI: hubAPI,
@@ -43,8 +53,10 @@ func Setup(cfg *viper.Viper, hubAPI *api.API, imageStore *pg.ImageStore) *Handle
 
 		Organizations:     org.NewHandlers(hubAPI),
 		User:              user.NewHandlers(hubAPI, cfg),
-		Packages:          pkg.NewHandlers(hubAPI),
+		Packages:          pkg.NewHandlers(hubAPI, archiveStore),
 		ChartRepositories: chartrepo.NewHandlers(hubAPI),
+		Hosted:            hosted.NewHandlers(hubAPI, archiveStore),
+		Mirror:            mirror.NewHandlers(hubAPI, archiveStore),
 		Static:            static.NewHandlers(cfg, imageStore),
 	}
 	h.setupRouter()
@@ -74,6 +86,15 @@ func (h *Handlers) setupRouter() {
 		})
 		r.Route("/package", func(r chi.Router) {
 			r.Route("/chart/{repoName}/{packageName}", func(r chi.Router) {
+				r.Get("/dependents", h.Packages.GetDependents)
+				r.Get("/diff", h.Packages.GetDiff)
+				r.Get("/{version}/files", h.Packages.GetFiles)
+				r.Get("/{version}/files/*", h.Packages.GetFile)
+				r.Get("/{version}/values", h.Packages.GetValues)
+				r.Get("/{version}/values-schema", h.Packages.GetValuesSchema)
+				r.Get("/{version}/values-docs", h.Packages.GetValuesDocs)
+				r.Post("/{version}/validate-values", h.Packages.ValidateValues)
+				r.With(h.User.RequireLogin).Post("/{version}/render", h.Packages.RenderTemplates)
 				r.Get("/{version}", h.Packages.Get)
 				r.Get("/", h.Packages.Get)
 			})
@@ -94,6 +115,14 @@ func (h *Handlers) setupRouter() {
 			r.Route("/chart-repository/{repoName}", func(r chi.Router) {
 				r.Put("/", h.ChartRepositories.Update)
 				r.Delete("/", h.ChartRepositories.Delete)
+				r.Get("/tracking-runs", h.ChartRepositories.GetTrackingRuns)
+				r.Get("/tracking-run/{trackingRunID}/errors", h.ChartRepositories.GetTrackingRunErrors)
+				r.Post("/track", h.ChartRepositories.RequestTracking)
+				r.Get("/tracking-request/{trackingRequestID}", h.ChartRepositories.GetTrackingRequest)
+				r.Post("/webhook", h.ChartRepositories.GenerateWebhookSecret)
+				r.Delete("/webhook", h.ChartRepositories.DeleteWebhookSecret)
+				r.Put("/package/{packageName}/{version}/yank", h.Packages.Yank)
+				r.Delete("/package/{packageName}/{version}/yank", h.Packages.Unyank)
 			})
 		})
 		r.With(h.User.RequireLogin).Post("/orgs", h.Organizations.Add)
@@ -114,8 +143,29 @@ func (h *Handlers) setupRouter() {
 			r.Route("/chart-repository/{repoName}", func(r chi.Router) {
 				r.Put("/", h.ChartRepositories.Update)
 				r.Delete("/", h.ChartRepositories.Delete)
+				r.Get("/tracking-runs", h.ChartRepositories.GetTrackingRuns)
+				r.Get("/tracking-run/{trackingRunID}/errors", h.ChartRepositories.GetTrackingRunErrors)
+				r.Post("/track", h.ChartRepositories.RequestTracking)
+				r.Get("/tracking-request/{trackingRequestID}", h.ChartRepositories.GetTrackingRequest)
+				r.Post("/webhook", h.ChartRepositories.GenerateWebhookSecret)
+				r.Delete("/webhook", h.ChartRepositories.DeleteWebhookSecret)
+				r.Put("/package/{packageName}/{version}/yank", h.Packages.Yank)
+				r.Delete("/package/{packageName}/{version}/yank", h.Packages.Unyank)
 			})
 		})
+		r.Post("/webhook/chart-repository/{chartRepositoryID}", h.ChartRepositories.Webhook)
+		r.Route("/hosted", func(r chi.Router) {
+			r.With(h.User.RequireLoginOrBasicAuth).Post("/api/{repoName}/charts", h.Hosted.UploadChart)
+			r.Get("/{repoName}/index.yaml", h.Hosted.GetIndex)
+			r.Get("/{repoName}/charts/{packageName}/{version}/{fileName}", h.Hosted.GetArchive)
+		})
+		if h.cfg.GetBool("server.mirror.enabled") {
+			r.Route("/mirror", func(r chi.Router) {
+				r.Get("/index.yaml", h.Mirror.GetIndex)
+				r.Get("/{repoName}/index.yaml", h.Mirror.GetRepositoryIndex)
+				r.Get("/{repoName}/charts/{packageName}/{version}/{fileName}", h.Mirror.GetArchive)
+			})
+		}
 		r.Post("/verify-email", h.User.VerifyEmail)
 		r.Post("/login", h.User.Login)
 		r.With(h.User.RequireLogin).Get("/logout", h.User.Logout)
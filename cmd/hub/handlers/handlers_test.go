@@ -146,10 +146,10 @@ type handlersWrapper struct {
 func newHandlersWrapper() *handlersWrapper {
 	cfg := viper.New()
 	db := &tests.DBMock{}
-	hubAPI := api.New(db, nil)
+	hubAPI := api.New(db, nil, nil)
 
 	return &handlersWrapper{
 		db: db,
-		h:  Setup(cfg, hubAPI, nil),
+		h:  Setup(cfg, hubAPI, nil, nil),
 	}
 }
@@ -19,6 +19,14 @@ func RenderJSON(w http.ResponseWriter, jsonData []byte, cacheMaxAge time.Duratio
 	_, _ = w.Write(jsonData)
 }
 
+// RenderYAML is a helper to write the yaml data provided to the given http
+// response writer, setting the appropriate content type and cache
+func RenderYAML(w http.ResponseWriter, yamlData []byte, cacheMaxAge time.Duration) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(cacheMaxAge.Seconds())))
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(yamlData)
+}
+
 // GetBaseURL is a helper function that builds the base url from the request
 // provided.
 func GetBaseURL(r *http.Request) string {
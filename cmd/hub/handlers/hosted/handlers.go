@@ -0,0 +1,408 @@
+package hosted
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive/pg"
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/helm"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	// maxChartSize represents the maximum size of the charts archives that
+	// can be uploaded.
+	maxChartSize = 10 * 1024 * 1024
+
+	// maxChartFilesSize represents the maximum size of the files of the
+	// charts archives uploaded once decompressed.
+	maxChartFilesSize = 50 * 1024 * 1024
+
+	// maxProvenanceSize represents the maximum size of the provenance files
+	// that can be uploaded.
+	maxProvenanceSize = 1 * 1024 * 1024
+
+	// maxConcurrentRenders represents the maximum number of charts uploaded
+	// that can be linted and rendered concurrently.
+	maxConcurrentRenders = 4
+
+	// renderTimeout represents the maximum time linting a chart uploaded and
+	// rendering its templates can take. Charts are processed while serving
+	// the upload request, so it is kept well under the hub server write
+	// timeout, so that the response is always delivered.
+	renderTimeout = 2 * time.Second
+
+	// maxRenderedSize represents the maximum size of the manifests produced
+	// when rendering a chart uploaded.
+	maxRenderedSize = 10 * 1024 * 1024
+)
+
+var (
+	// chartNameRE is a regexp used to validate the name of the charts
+	// uploaded.
+	chartNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+	// errFileTooLarge indicates that the file uploaded exceeds the maximum
+	// size allowed.
+	errFileTooLarge = errors.New("file too large")
+)
+
+// Handlers represents a group of http handlers in charge of handling hosted
+// chart repositories, whose charts are uploaded to the hub using the
+// ChartMuseum API and served from it as Helm repositories.
+type Handlers struct {
+	hubAPI       *api.API
+	archiveStore *pg.ArchiveStore
+	renderer     *helm.Renderer
+	logger       zerolog.Logger
+}
+
+// NewHandlers creates a new Handlers instance.
+func NewHandlers(hubAPI *api.API, archiveStore *pg.ArchiveStore) *Handlers {
+	return &Handlers{
+		hubAPI:       hubAPI,
+		archiveStore: archiveStore,
+		renderer:     helm.NewRenderer(maxConcurrentRenders, renderTimeout, maxRenderedSize),
+		logger:       log.With().Str("handlers", "hosted").Logger(),
+	}
+}
+
+// GetArchive is an http handler used to get a chart archive, or its provenance
+// file, from a hosted chart repository.
+func (h *Handlers) GetArchive(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	fileName := chi.URLParam(r, "fileName")
+	if !strings.HasSuffix(fileName, ".tgz") && !strings.HasSuffix(fileName, ".tgz.prov") {
+		http.NotFound(w, r)
+		return
+	}
+	c, err := h.hubAPI.Packages.GetContent(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetArchive").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	digest, contentType := c.ArchiveDigest, "application/gzip"
+	if strings.HasSuffix(fileName, ".prov") {
+		digest, contentType = c.ProvenanceDigest, "text/plain; charset=utf-8"
+	}
+	if digest == "" {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := h.archiveStore.GetArchive(r.Context(), digest)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetArchive").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(helpers.DefaultAPICacheMaxAge.Seconds())))
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+// GetIndex is an http handler used to get the Helm repository index file of a
+// hosted chart repository.
+func (h *Handlers) GetIndex(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	repo, err := h.hubAPI.ChartRepositories.GetByName(r.Context(), repoName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Str("repoName", repoName).Str("method", "GetIndex").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	if !repo.Hosted {
+		http.NotFound(w, r)
+		return
+	}
+	entries, err := h.hubAPI.ChartRepositories.GetMirrorIndexEntries(r.Context(), repoName)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		h.logger.Error().Err(err).Str("repoName", repoName).Str("method", "GetIndex").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	data, err := chartrepo.BuildMirrorIndex(entries, false, time.Now())
+	if err != nil {
+		h.logger.Error().Err(err).Str("repoName", repoName).Str("method", "GetIndex").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	helpers.RenderYAML(w, data, 0)
+}
+
+// UploadChart is an http handler used to upload a chart archive, and
+// optionally its provenance file, to a hosted chart repository using the
+// ChartMuseum API. The chart can be sent as the request body or as the chart
+// field of a multipart form, which may include the provenance file in the
+// prov field. Once validated, the chart is stored and registered in the hub
+// right away, returning the problems found processing it (if any) as
+// warnings. The time spent linting the chart and rendering its templates is
+// limited, so that the response is delivered within the server timeouts. The user doing the request must own the chart repository or
+// belong to the organization which owns it.
+func (h *Handlers) UploadChart(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+
+	// Check the chart repository is hosted and the user can upload charts
+	repo, err := h.hubAPI.ChartRepositories.GetByName(r.Context(), repoName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			renderError(w, "chart repository not found", http.StatusNotFound)
+		} else {
+			h.logger.Error().Err(err).Str("repoName", repoName).Str("method", "UploadChart").Send()
+			renderError(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	if !repo.Hosted {
+		renderError(w, "chart repository not found", http.StatusNotFound)
+		return
+	}
+	hasAccess, err := h.hubAPI.ChartRepositories.HasAccess(r.Context(), repoName)
+	if err != nil {
+		h.logger.Error().Err(err).Str("repoName", repoName).Str("method", "UploadChart").Send()
+		renderError(w, "", http.StatusInternalServerError)
+		return
+	}
+	if !hasAccess {
+		renderError(w, "insufficient privileges", http.StatusForbidden)
+		return
+	}
+
+	// Read and validate the chart archive and provenance file provided
+	archive, prov, err := readUpload(r)
+	if err != nil {
+		if errors.Is(err, errFileTooLarge) {
+			renderError(w, err.Error(), http.StatusRequestEntityTooLarge)
+		} else {
+			renderError(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	files, err := chartrepo.LoadArchiveFiles(archive, maxChartFilesSize)
+	if err != nil {
+		if errors.Is(err, chartrepo.ErrArchiveTooLarge) {
+			renderError(w, err.Error(), http.StatusRequestEntityTooLarge)
+		} else {
+			renderError(w, fmt.Sprintf("invalid chart archive: %v", err), http.StatusBadRequest)
+		}
+		return
+	}
+	chart, err := loader.LoadFiles(files)
+	if err != nil {
+		renderError(w, fmt.Sprintf("invalid chart archive: %v", err), http.StatusBadRequest)
+		return
+	}
+	md := chart.Metadata
+	if !chartNameRE.MatchString(md.Name) {
+		renderError(w, "invalid chart name", http.StatusBadRequest)
+		return
+	}
+	if _, err := semver.StrictNewVersion(md.Version); err != nil {
+		renderError(w, "invalid chart version: must be a valid semantic version", http.StatusBadRequest)
+		return
+	}
+	input := &pkg.GetInput{
+		ChartRepositoryName: repoName,
+		PackageName:         md.Name,
+		Version:             md.Version,
+	}
+	// Existing versions are rejected early to avoid storing their archives
+	// again, but uniqueness is enforced when registering the package
+	_, err = h.hubAPI.Packages.GetContent(r.Context(), input)
+	switch {
+	case err == nil:
+		renderError(w, "file already exists", http.StatusConflict)
+		return
+	case !errors.Is(err, pgx.ErrNoRows):
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "UploadChart").Send()
+		renderError(w, "", http.StatusInternalServerError)
+		return
+	}
+	var signer string
+	if prov != nil && repo.Keyring != nil {
+		keyring, err := chartrepo.ParseKeyring(*repo.Keyring)
+		if err != nil {
+			h.logger.Error().Err(err).Str("repoName", repoName).Str("method", "UploadChart").Send()
+			renderError(w, "", http.StatusInternalServerError)
+			return
+		}
+		archiveName := fmt.Sprintf("%s-%s.tgz", md.Name, md.Version)
+		signer, err = chartrepo.VerifyProvenance(keyring, archive, archiveName, prov)
+		if err != nil {
+			renderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Build package from the chart before storing anything, so that uploads
+	// rejected because too many charts are being processed can be retried
+	u := repo.URL + "/" + chartrepo.MirrorArchivePath(md.Name, md.Name, md.Version)
+	p, errs, err := helm.NewPackage(chart, u, h.renderer)
+	if err != nil {
+		w.Header().Set("Retry-After", "5")
+		renderError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// Store chart archive and provenance file
+	digest, err := h.archiveStore.SaveArchive(r.Context(), archive)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "UploadChart").Send()
+		renderError(w, "", http.StatusInternalServerError)
+		return
+	}
+	var provDigest string
+	if prov != nil {
+		provDigest, err = h.archiveStore.SaveArchive(r.Context(), prov)
+		if err != nil {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "UploadChart").Send()
+			renderError(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Register package
+	p.LogoURL = md.Icon
+	p.Digest = digest
+	p.ArchiveDigest = digest
+	p.ChartMetadata, _ = json.Marshal(md)
+	p.ProvenanceDigest = provDigest
+	p.Signed = prov != nil
+	p.SignatureVerified = signer != ""
+	p.Signer = signer
+	p.ChartRepository = &hub.ChartRepository{
+		ChartRepositoryID: repo.ChartRepositoryID,
+	}
+	if err := h.hubAPI.Packages.Register(r.Context(), p); err != nil {
+		if errors.Is(err, pkg.ErrVersionAlreadyExists) {
+			renderError(w, "file already exists", http.StatusConflict)
+			return
+		}
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "UploadChart").Send()
+		renderError(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	// Problems found processing the chart that did not prevent registering it
+	// are returned as warnings
+	resp := struct {
+		Saved    bool     `json:"saved"`
+		Warnings []string `json:"warnings,omitempty"`
+	}{
+		Saved: true,
+	}
+	for _, e := range errs {
+		resp.Warnings = append(resp.Warnings, e.Message)
+	}
+	jsonData, _ := json.Marshal(resp)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(jsonData)
+}
+
+// readUpload reads the chart archive and the provenance file (if any) from
+// the request provided.
+func readUpload(r *http.Request) ([]byte, []byte, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		archive, err := readFile(r.Body, maxChartSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return archive, nil, nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid multipart form: %w", err)
+	}
+	var archive, prov []byte
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		switch part.FormName() {
+		case "chart":
+			archive, err = readPart(part, maxChartSize)
+		case "prov":
+			prov, err = readPart(part, maxProvenanceSize)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if archive == nil {
+		return nil, nil, errors.New("chart not provided")
+	}
+	return archive, prov, nil
+}
+
+// readPart reads the content of the multipart form part provided, limiting it
+// to the maximum size given.
+func readPart(part *multipart.Part, maxSize int64) ([]byte, error) {
+	defer part.Close()
+	return readFile(part, maxSize)
+}
+
+// readFile reads the content of the file provided, limiting it to the maximum
+// size given.
+func readFile(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
+
+// renderError writes the error message provided to the http response writer
+// in the format used by ChartMuseum, so that clients like the helm push plugin
+// can display it.
+func renderError(w http.ResponseWriter, msg string, code int) {
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	jsonData, _ := json.Marshal(map[string]string{"error": msg})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(jsonData)
+}
@@ -0,0 +1,698 @@
+package hosted
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive/pg"
+	"github.com/artifacthub/hub/internal/helm"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestMain(m *testing.M) {
+	helm.HandleRenderProcess()
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+func TestGetArchive(t *testing.T) {
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	getArchiveDBQuery := "select get_chart_archive($1::text)"
+	contentJSON := []byte(`{"archive_digest": "archiveDigest", "provenance_digest": "provDigest"}`)
+
+	t.Run("invalid file name", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r, "fileName", "chart-1.0.0.tar")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("non existing package version", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r, "fileName", "chart-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting package content", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r, "fileName", "chart-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("provenance file not available", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return([]byte(`{"archive_digest": "archiveDigest"}`), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r, "fileName", "chart-1.0.0.tgz.prov")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting archive", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON, nil)
+		hw.db.On("QueryRow", getArchiveDBQuery, "archiveDigest").Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r, "fileName", "chart-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("archive and provenance file returned", func(t *testing.T) {
+		testCases := []struct {
+			fileName            string
+			digest              string
+			expectedContentType string
+		}{
+			{"chart-1.0.0.tgz", "archiveDigest", "application/gzip"},
+			{"chart-1.0.0.tgz.prov", "provDigest", "text/plain; charset=utf-8"},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.fileName, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON, nil)
+				hw.db.On("QueryRow", getArchiveDBQuery, tc.digest).Return([]byte("data"), nil)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				r = withURLParams(r, "fileName", tc.fileName)
+				hw.h.GetArchive(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+				data, _ := ioutil.ReadAll(resp.Body)
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
+				assert.Equal(t, []byte("data"), data)
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestGetIndex(t *testing.T) {
+	getRepoDBQuery := "select get_chart_repository_by_name($1::text)"
+	getEntriesDBQuery := "select get_mirror_index_entries($1::text)"
+
+	t.Run("non existing chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart repository not hosted", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(false, ""), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting index entries", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", getEntriesDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("empty index returned", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", getEntriesDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+		assert.Contains(t, string(data), "entries: {}")
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("index returned", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", getEntriesDBQuery, mock.Anything).Return([]byte(`[{
+			"chart_repository_name": "repo1",
+			"package_name": "chart",
+			"metadata": {"name": "chart", "version": "1.0.0"},
+			"digest": "digest"
+		}]`), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withURLParams(r)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(data), "- charts/chart/1.0.0/chart-1.0.0.tgz")
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestUploadChart(t *testing.T) {
+	getRepoDBQuery := "select get_chart_repository_by_name($1::text)"
+	hasAccessDBQuery := "select user_has_access_to_chart_repository($1::uuid, $2::text)"
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	saveArchiveDBQuery := "select register_chart_archive($1::text, $2::bytea)"
+	registerDBQuery := "select register_package($1::jsonb)"
+	archive := newChartArchive(t, "chart", "1.0.0")
+	signer, err := openpgp.NewEntity("signer1", "", "signer1@email.com", nil)
+	require.NoError(t, err)
+	keyring := armoredPublicKey(t, signer)
+
+	t.Run("non existing chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, `{"error":"chart repository not found"}`, string(data))
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart repository not hosted", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(false, ""), nil)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("user has no access to the chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(false, nil)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid chart provided", func(t *testing.T) {
+		testCases := []struct {
+			description        string
+			body               []byte
+			expectedStatusCode int
+		}{
+			{
+				"chart too large",
+				bytes.Repeat([]byte("a"), maxChartSize+1),
+				http.StatusRequestEntityTooLarge,
+			},
+			{
+				"chart files too large once decompressed",
+				saveChartArchive(t, &chart.Chart{
+					Metadata: &chart.Metadata{
+						APIVersion: chart.APIVersionV2,
+						Name:       "chart",
+						Version:    "1.0.0",
+					},
+					Files: []*chart.File{
+						{Name: "large.txt", Data: make([]byte, maxChartFilesSize+1)},
+					},
+				}),
+				http.StatusRequestEntityTooLarge,
+			},
+			{
+				"invalid chart archive",
+				[]byte("invalid"),
+				http.StatusBadRequest,
+			},
+			{
+				"invalid chart name",
+				newChartArchive(t, "Chart", "1.0.0"),
+				http.StatusBadRequest,
+			},
+			{
+				"invalid chart version",
+				newChartArchive(t, "chart", "1.0"),
+				http.StatusBadRequest,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.description, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+				hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+
+				w := httptest.NewRecorder()
+				r := newUploadRequest(bytes.NewReader(tc.body))
+				hw.h.UploadChart(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("chart version already exists", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return([]byte(`{}`), nil)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("too many charts being processed", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.h.renderer = helm.NewRenderer(0, time.Minute, 1024)
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, "5", resp.Header.Get("Retry-After"))
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid provenance file", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, keyring), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r := newMultipartUploadRequest(t, archive, []byte("invalid"))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error storing chart archive", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error registering package", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(nil)
+		hw.db.On("Exec", registerDBQuery, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart version registered concurrently", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(nil)
+		hw.db.On("Exec", registerDBQuery, mock.Anything).Return(&pgconn.PgError{Code: "23505"})
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart uploaded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(nil)
+		hw.db.On("Exec", registerDBQuery, mock.MatchedBy(func(pJSON []byte) bool {
+			var p *hub.Package
+			_ = json.Unmarshal(pJSON, &p)
+			return p.Name == "chart" &&
+				p.Version == "1.0.0" &&
+				p.ContentURL == "http://hub.url/api/v1/hosted/repo1/charts/chart/1.0.0/chart-1.0.0.tgz" &&
+				p.ArchiveDigest != "" &&
+				p.ArchiveDigest == p.Digest &&
+				len(p.ChartMetadata) > 0 &&
+				!p.Signed &&
+				p.ChartRepository.ChartRepositoryID == "00000000-0000-0000-0000-000000000001"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, `{"saved":true}`, string(data))
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart uploaded with warnings", func(t *testing.T) {
+		archive := saveChartArchive(t, &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion:  chart.APIVersionV2,
+				Name:        "chart",
+				Version:     "1.0.0",
+				KubeVersion: "invalid",
+			},
+		})
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(nil)
+		hw.db.On("Exec", registerDBQuery, mock.Anything).Return(nil)
+
+		w := httptest.NewRecorder()
+		r := newUploadRequest(bytes.NewReader(archive))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var body struct {
+			Saved    bool     `json:"saved"`
+			Warnings []string `json:"warnings"`
+		}
+		require.NoError(t, json.Unmarshal(data, &body))
+		assert.True(t, body.Saved)
+		require.Len(t, body.Warnings, 1)
+		assert.Contains(t, body.Warnings[0], "error parsing kubeVersion of chart chart version 1.0.0")
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart uploaded along with its provenance file", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, ""), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(nil)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, []byte("prov")).Return(nil)
+		hw.db.On("Exec", registerDBQuery, mock.MatchedBy(func(pJSON []byte) bool {
+			var p *hub.Package
+			_ = json.Unmarshal(pJSON, &p)
+			return p.ProvenanceDigest != "" && p.Signed && !p.SignatureVerified
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		r := newMultipartUploadRequest(t, archive, []byte("prov"))
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("chart uploaded along with a verified provenance file", func(t *testing.T) {
+		archiveSum := fmt.Sprintf("sha256:%x", sha256.Sum256(archive))
+		prov := clearSign(t, signer, []byte("name: chart\n...\nfiles:\n  chart-1.0.0.tgz: "+archiveSum+"\n"))
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getRepoDBQuery, "repo1").Return(repoJSON(true, keyring), nil)
+		hw.db.On("QueryRow", hasAccessDBQuery, "userID", "repo1").Return(true, nil)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, archive).Return(nil)
+		hw.db.On("Exec", saveArchiveDBQuery, mock.Anything, prov).Return(nil)
+		hw.db.On("Exec", registerDBQuery, mock.MatchedBy(func(pJSON []byte) bool {
+			var p *hub.Package
+			_ = json.Unmarshal(pJSON, &p)
+			return p.Signed && p.SignatureVerified && p.Signer == "signer1 <signer1@email.com>"
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		r := newMultipartUploadRequest(t, archive, prov)
+		hw.h.UploadChart(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+type handlersWrapper struct {
+	db *tests.DBMock
+	h  *Handlers
+}
+
+func newHandlersWrapper() *handlersWrapper {
+	db := &tests.DBMock{}
+	hubAPI := api.New(db, nil, nil)
+
+	return &handlersWrapper{
+		db: db,
+		h:  NewHandlers(hubAPI, pg.NewArchiveStore(db)),
+	}
+}
+
+func newChartArchive(t *testing.T, name, version string) []byte {
+	return saveChartArchive(t, &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       name,
+			Version:    version,
+		},
+	})
+}
+
+func saveChartArchive(t *testing.T, c *chart.Chart) []byte {
+	dir, err := ioutil.TempDir("", "artifacthub-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	archivePath, err := chartutil.Save(c, dir)
+	require.NoError(t, err)
+	archive, err := ioutil.ReadFile(archivePath)
+	require.NoError(t, err)
+	return archive
+}
+
+func newUploadRequest(body *bytes.Reader) *http.Request {
+	r, _ := http.NewRequest("POST", "http://hub.url/", body)
+	return withURLParams(r)
+}
+
+func newMultipartUploadRequest(t *testing.T, archive, prov []byte) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for field, data := range map[string][]byte{"chart": archive, "prov": prov} {
+		fw, err := mw.CreateFormFile(field, field)
+		require.NoError(t, err)
+		_, err = fw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	r := newUploadRequest(bytes.NewReader(body.Bytes()))
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func repoJSON(hosted bool, keyring string) []byte {
+	repo := map[string]interface{}{
+		"chart_repository_id": "00000000-0000-0000-0000-000000000001",
+		"name":                "repo1",
+		"url":                 "http://hub.url/api/v1/hosted/repo1",
+		"hosted":              hosted,
+	}
+	if keyring != "" {
+		repo["keyring"] = keyring
+	}
+	data, _ := json.Marshal(repo)
+	return data
+}
+
+func withURLParams(r *http.Request, keysValues ...string) *http.Request {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName", "packageName", "version"},
+			Values: []string{"repo1", "chart", "1.0.0"},
+		},
+	}
+	for i := 0; i+1 < len(keysValues); i += 2 {
+		rctx.URLParams.Add(keysValues[i], keysValues[i+1])
+	}
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, hub.UserIDKey, "userID")
+	return r.WithContext(ctx)
+}
+
+func armoredPublicKey(t *testing.T, e *openpgp.Entity) string {
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, e.Serialize(w))
+	require.NoError(t, w.Close())
+	return b.String()
+}
+
+func clearSign(t *testing.T, e *openpgp.Entity, msg []byte) []byte {
+	var b bytes.Buffer
+	w, err := clearsign.Encode(&b, e.PrivateKey, nil)
+	require.NoError(t, err)
+	_, err = w.Write(msg)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
@@ -0,0 +1,111 @@
+package mirror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive/pg"
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Handlers represents a group of http handlers in charge of serving the charts
+// archives mirrored by the hub as Helm repositories.
+type Handlers struct {
+	hubAPI       *api.API
+	archiveStore *pg.ArchiveStore
+	logger       zerolog.Logger
+}
+
+// NewHandlers creates a new Handlers instance.
+func NewHandlers(hubAPI *api.API, archiveStore *pg.ArchiveStore) *Handlers {
+	return &Handlers{
+		hubAPI:       hubAPI,
+		archiveStore: archiveStore,
+		logger:       log.With().Str("handlers", "mirror").Logger(),
+	}
+}
+
+// GetArchive is an http handler used to get a chart archive from the mirror.
+func (h *Handlers) GetArchive(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	if !strings.HasSuffix(chi.URLParam(r, "fileName"), ".tgz") {
+		http.NotFound(w, r)
+		return
+	}
+	c, err := h.hubAPI.Packages.GetContent(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetArchive").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	if c.ArchiveDigest == "" {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := h.archiveStore.GetArchive(r.Context(), c.ArchiveDigest)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetArchive").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(helpers.DefaultAPICacheMaxAge.Seconds())))
+	w.Header().Set("Content-Type", "application/gzip")
+	_, _ = w.Write(data)
+}
+
+// GetIndex is an http handler used to get a Helm repository index file
+// combining the charts versions mirrored from all chart repositories.
+func (h *Handlers) GetIndex(w http.ResponseWriter, r *http.Request) {
+	h.renderIndex(w, r, "", "GetIndex")
+}
+
+// GetRepositoryIndex is an http handler used to get the Helm repository index
+// file of the charts versions mirrored from a given chart repository.
+func (h *Handlers) GetRepositoryIndex(w http.ResponseWriter, r *http.Request) {
+	h.renderIndex(w, r, chi.URLParam(r, "repoName"), "GetRepositoryIndex")
+}
+
+// renderIndex is a helper that builds and renders the index file of the
+// mirrored charts versions of the chart repository provided (or all of them
+// if no name is provided).
+func (h *Handlers) renderIndex(w http.ResponseWriter, r *http.Request, repoName, method string) {
+	entries, err := h.hubAPI.ChartRepositories.GetMirrorIndexEntries(r.Context(), repoName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Str("repoName", repoName).Str("method", method).Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	data, err := chartrepo.BuildMirrorIndex(entries, repoName == "", time.Now())
+	if err != nil {
+		h.logger.Error().Err(err).Str("repoName", repoName).Str("method", method).Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	helpers.RenderYAML(w, data, helpers.DefaultAPICacheMaxAge)
+}
@@ -0,0 +1,290 @@
+package mirror
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive/pg"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+func TestGetArchive(t *testing.T) {
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	getArchiveDBQuery := "select get_chart_archive($1::text)"
+
+	t.Run("invalid file name", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tar")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("non existing package version", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting package content", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("package version not mirrored", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return([]byte(`{"content_url": "http://repo1.url/pkg1-1.0.0.tgz"}`), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("archive not found", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return([]byte(`{"archive_digest": "digest"}`), nil)
+		hw.db.On("QueryRow", getArchiveDBQuery, "digest").Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting archive", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return([]byte(`{"archive_digest": "digest"}`), nil)
+		hw.db.On("QueryRow", getArchiveDBQuery, "digest").Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("archive returned", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return([]byte(`{"archive_digest": "digest"}`), nil)
+		hw.db.On("QueryRow", getArchiveDBQuery, "digest").Return([]byte("archiveData"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withFileName(r, "pkg1-1.0.0.tgz")
+		hw.h.GetArchive(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/gzip", h.Get("Content-Type"))
+		assert.Equal(t, []byte("archiveData"), data)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestGetIndex(t *testing.T) {
+	dbQuery := "select get_mirror_index_entries($1::text)"
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid entry metadata", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything).Return([]byte(`[{"metadata": {}}]`), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("index returned", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything).Return([]byte(`[{
+			"chart_repository_name": "repo1",
+			"package_name": "pkg1",
+			"metadata": {"name": "pkg1", "version": "1.0.0"},
+			"digest": "digest"
+		}]`), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/yaml", h.Get("Content-Type"))
+		assert.Contains(t, string(data), "repo1-pkg1:")
+		assert.Contains(t, string(data), "- repo1/charts/pkg1/1.0.0/pkg1-1.0.0.tgz")
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestGetRepositoryIndex(t *testing.T) {
+	dbQuery := "select get_mirror_index_entries($1::text)"
+
+	t.Run("non existing chart repository", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withRepoName(r, "repo1")
+		hw.h.GetRepositoryIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withRepoName(r, "repo1")
+		hw.h.GetRepositoryIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("index returned", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, mock.Anything).Return([]byte(`[{
+			"chart_repository_name": "repo1",
+			"package_name": "pkg1",
+			"metadata": {"name": "pkg1", "version": "1.0.0"},
+			"digest": "digest"
+		}]`), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = withRepoName(r, "repo1")
+		hw.h.GetRepositoryIndex(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/yaml", h.Get("Content-Type"))
+		assert.Contains(t, string(data), "  pkg1:")
+		assert.Contains(t, string(data), "- charts/pkg1/1.0.0/pkg1-1.0.0.tgz")
+		hw.db.AssertExpectations(t)
+	})
+}
+
+type handlersWrapper struct {
+	db *tests.DBMock
+	h  *Handlers
+}
+
+func newHandlersWrapper() *handlersWrapper {
+	db := &tests.DBMock{}
+	hubAPI := api.New(db, nil, nil)
+
+	return &handlersWrapper{
+		db: db,
+		h:  NewHandlers(hubAPI, pg.NewArchiveStore(db)),
+	}
+}
+
+func withFileName(r *http.Request, fileName string) *http.Request {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName", "packageName", "version", "fileName"},
+			Values: []string{"repo1", "pkg1", "1.0.0", fileName},
+		},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func withRepoName(r *http.Request, repoName string) *http.Request {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName"},
+			Values: []string{repoName},
+		},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
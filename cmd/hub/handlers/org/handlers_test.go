@@ -438,7 +438,7 @@ type handlersWrapper struct {
 func newHandlersWrapper() *handlersWrapper {
 	db := &tests.DBMock{}
 	es := &tests.EmailSenderMock{}
-	hubAPI := api.New(db, es)
+	hubAPI := api.New(db, es, nil)
 
 	return &handlersWrapper{
 		db: db,
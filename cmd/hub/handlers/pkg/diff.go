@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/helm"
+)
+
+// GetDiff is an http handler used to get the differences in the metadata,
+// default values and templates between two versions of a given chart.
+func (h *Handlers) GetDiff(w http.ResponseWriter, r *http.Request) {
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" {
+		http.Error(w, "invalid from: version not provided", http.StatusBadRequest)
+		return
+	}
+	if to == "" {
+		http.Error(w, "invalid to: version not provided", http.StatusBadRequest)
+		return
+	}
+	fromFiles, ok := h.loadArchiveFiles(w, r, from, "GetDiff")
+	if !ok {
+		return
+	}
+	toFiles, ok := h.loadArchiveFiles(w, r, to, "GetDiff")
+	if !ok {
+		return
+	}
+	diff, err := helm.DiffArchives(fromFiles, toFiles)
+	if err != nil {
+		h.logger.Error().Err(err).Str("from", from).Str("to", to).Str("method", "GetDiff").Send()
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	jsonData, _ := json.Marshal(diff)
+	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetDiff(t *testing.T) {
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	getRepoDBQuery := "select get_chart_repository_by_name($1::text)"
+	s := newChartArchiveServer(t)
+	defer s.Close()
+	forVersion := func(version string) interface{} {
+		return mock.MatchedBy(func(input []byte) bool {
+			return strings.Contains(string(input), `"version":"`+version+`"`)
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []string{
+			"",
+			"from=1.0.0",
+			"to=1.1.0",
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc, func(t *testing.T) {
+				hw := newHandlersWrapper()
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/?"+tc, nil)
+				hw.h.GetDiff(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("non existing package version", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, forVersion("1.0.0")).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+		hw.db.On("QueryRow", getContentDBQuery, forVersion("2.0.0")).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0&to=2.0.0", nil)
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0&to=1.1.0", nil)
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("diff returned", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, forVersion("1.0.0")).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getContentDBQuery, forVersion("1.1.0")).Return(contentJSON(s.URL+"/chart-1.1.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?from=1.0.0&to=1.1.0", nil)
+		hw.h.GetDiff(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.JSONEq(t, `{
+			"metadata": [
+				{"key": "version", "change": "changed", "from": "1.0.0", "to": "1.1.0"}
+			],
+			"values": [],
+			"templates": [
+				{
+					"name": "templates/configmap.yaml",
+					"change": "changed",
+					"diff": "--- templates/configmap.yaml\n+++ templates/configmap.yaml\n@@ -1,4 +1,4 @@\n apiVersion: v1\n kind: ConfigMap\n metadata:\n-  name: {{ .Release.Name }}\n+  name: {{ .Release.Name }}-config\n"
+				}
+			]
+		}`, string(data))
+		hw.db.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	// maxArchiveSize represents the maximum size of the charts archives whose
+	// files can be browsed.
+	maxArchiveSize = 10 * 1024 * 1024
+
+	// maxArchiveFilesSize represents the maximum size of the files of the
+	// charts archives whose files can be browsed, once decompressed.
+	maxArchiveFilesSize = 50 * 1024 * 1024
+
+	// archivesCacheSize represents the maximum size of the files of the charts
+	// archives kept in memory.
+	archivesCacheSize = 100 * 1024 * 1024
+
+	// maxFileSize represents the maximum size of the files that can be
+	// requested from a chart archive.
+	maxFileSize = 1024 * 1024
+)
+
+// GetFile is an http handler used to get a file from the archive of a given
+// chart version.
+func (h *Handlers) GetFile(w http.ResponseWriter, r *http.Request) {
+	files, ok := h.loadArchiveFiles(w, r, chi.URLParam(r, "version"), "GetFile")
+	if !ok {
+		return
+	}
+	name := chi.URLParam(r, "*")
+	for _, f := range files {
+		if f.Name != name {
+			continue
+		}
+		if len(f.Data) > maxFileSize {
+			http.Error(w, "file too large", http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(helpers.DefaultAPICacheMaxAge.Seconds())))
+		w.Header().Set("Content-Type", fileContentType(f.Name, f.Data))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		_, _ = w.Write(f.Data)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// GetFiles is an http handler used to list the files available in the
+// archive of a given chart version.
+func (h *Handlers) GetFiles(w http.ResponseWriter, r *http.Request) {
+	files, ok := h.loadArchiveFiles(w, r, chi.URLParam(r, "version"), "GetFiles")
+	if !ok {
+		return
+	}
+	type fileInfo struct {
+		Name string `json:"name"`
+		Size int    `json:"size"`
+	}
+	list := make([]*fileInfo, 0, len(files))
+	for _, f := range files {
+		list = append(list, &fileInfo{Name: f.Name, Size: len(f.Data)})
+	}
+	jsonData, _ := json.Marshal(list)
+	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
+}
+
+// loadArchiveFiles is a helper that loads the files of the archive of the
+// given version of the chart identified by the request provided. When an error
+// occurs, the appropriate response is written to the response writer given and
+// false is returned.
+func (h *Handlers) loadArchiveFiles(
+	w http.ResponseWriter,
+	r *http.Request,
+	version string,
+	method string,
+) ([]*loader.BufferedFile, bool) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             version,
+	}
+	content, err := h.hubAPI.Packages.GetContent(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", method).Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	repo, err := h.hubAPI.ChartRepositories.GetByName(r.Context(), content.ChartRepositoryName)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", method).Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return nil, false
+	}
+	if repo == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	files, err := h.archives.Load(r.Context(), repo, content.URL, content.Digest, content.ArchiveDigest)
+	if err != nil {
+		switch {
+		case errors.Is(err, chartrepo.ErrArchiveTooLarge):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, chartrepo.ErrArchiveNotAvailable), errors.Is(err, pgx.ErrNoRows):
+			http.NotFound(w, r)
+		default:
+			h.logger.Error().Err(err).Str("url", content.URL).Str("method", method).Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	return files, true
+}
+
+// fileContentType returns the content type that should be used when serving
+// the chart archive file provided. Files that are not known to be yaml or
+// json are served as plain text or as binary data, so that they are never
+// rendered by browsers.
+func fileContentType(name string, data []byte) string {
+	switch path.Ext(name) {
+	case ".yaml", ".yml":
+		return "application/yaml"
+	case ".json":
+		return "application/json"
+	}
+	if strings.HasPrefix(http.DetectContentType(data), "text/") {
+		return "text/plain; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
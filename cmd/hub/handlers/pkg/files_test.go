@@ -0,0 +1,257 @@
+package pkg
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestGetFiles(t *testing.T) {
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	getRepoDBQuery := "select get_chart_repository_by_name($1::text)"
+	s := newChartArchiveServer(t)
+	defer s.Close()
+
+	t.Run("non existing package version", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFiles(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFiles(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("archive not available", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/not-found.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFiles(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("archive loaded from the archive store", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		content := []byte(`{"chart_repository_name": "repo1", "url": "` + s.URL + `/not-found.tgz", "digest": "digest", "archive_digest": "archiveDigest"}`)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(content, nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+		hw.db.On("QueryRow", "select get_chart_archive($1::text)", "archiveDigest").
+			Return(newChartArchive(t, "1.0.0", "name: {{ .Release.Name }}"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFiles(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart archive not available in the archive store", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		repo := []byte(`{"chart_repository_id": "00000000-0000-0000-0000-000000000001", "name": "repo1", "url": "` + s.URL + `", "hosted": true}`)
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repo, nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFiles(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("archive files listed", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFiles(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.JSONEq(t, `[
+			{"name": "Chart.yaml", "size": 42},
+			{"name": "templates/NOTES.txt", "size": 40},
+			{"name": "templates/configmap.yaml", "size": 69}
+		]`, string(data))
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestGetFile(t *testing.T) {
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	getRepoDBQuery := "select get_chart_repository_by_name($1::text)"
+	s := newChartArchiveServer(t)
+	defer s.Close()
+
+	t.Run("file not found", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetFile(w, withFilePath(r, "templates/not-found.yaml"))
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("file returned", func(t *testing.T) {
+		testCases := []struct {
+			path                string
+			expectedContentType string
+		}{
+			{"Chart.yaml", "application/yaml"},
+			{"templates/NOTES.txt", "text/plain; charset=utf-8"},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.path, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+				hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				hw.h.GetFile(w, withFilePath(r, tc.path))
+				resp := w.Result()
+				defer resp.Body.Close()
+				h := resp.Header
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, tc.expectedContentType, h.Get("Content-Type"))
+				assert.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+				assert.Equal(t, tests.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestFileContentType(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        []byte
+		contentType string
+	}{
+		{"values.yaml", []byte("key: value"), "application/yaml"},
+		{"values.schema.json", []byte("{}"), "application/json"},
+		{"templates/_helpers.tpl", []byte("{{- define \"name\" -}}"), "text/plain; charset=utf-8"},
+		{"README.html", []byte("<html><script></script></html>"), "text/plain; charset=utf-8"},
+		{"charts/dep-1.0.0.tgz", []byte{0x1f, 0x8b, 0x08, 0x00}, "application/octet-stream"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.contentType, fileContentType(tc.name, tc.data))
+		})
+	}
+}
+
+func newChartArchiveServer(t *testing.T) *httptest.Server {
+	archives := map[string][]byte{
+		"/chart-1.0.0.tgz": newChartArchive(t, "1.0.0", "name: {{ .Release.Name }}"),
+		"/chart-1.1.0.tgz": newChartArchive(t, "1.1.0", "name: {{ .Release.Name }}-config"),
+		"/chart-2.0.0.tgz": newChartArchive(t, "2.0.0", "{{ range until 100000 }}{{ range until 100000 }}{{ end }}{{ end }}"),
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archive, ok := archives[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+}
+
+func newChartArchive(t *testing.T, version, configMapName string) []byte {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "chart",
+			Version:    version,
+		},
+		Templates: []*chart.File{
+			{Name: "templates/NOTES.txt", Data: []byte("Thanks for installing {{ .Chart.Name }}\n")},
+			{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  " + configMapName + "\n")},
+		},
+	}
+	dir, err := ioutil.TempDir("", "artifacthub-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	archivePath, err := chartutil.Save(c, dir)
+	require.NoError(t, err)
+	archive, err := ioutil.ReadFile(archivePath)
+	require.NoError(t, err)
+	return archive
+}
+
+func contentJSON(u string) []byte {
+	return []byte(`{"chart_repository_name": "repo1", "url": "` + u + `", "digest": "digest"}`)
+}
+
+func repoJSON(u string) []byte {
+	return []byte(`{"chart_repository_id": "00000000-0000-0000-0000-000000000001", "name": "repo1", "url": "` + u + `"}`)
+}
+
+func withFilePath(r *http.Request, p string) *http.Request {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"*"},
+			Values: []string{p},
+		},
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
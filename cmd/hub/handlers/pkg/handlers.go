@@ -9,6 +9,9 @@ import (
 
 	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
 	"github.com/artifacthub/hub/internal/api"
+	"github.com/artifacthub/hub/internal/archive"
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/helm"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/pkg"
 	"github.com/go-chi/chi"
@@ -20,15 +23,19 @@ import (
 // Handlers represents a group of http handlers in charge of handling packages
 // operations.
 type Handlers struct {
-	hubAPI *api.API
-	logger zerolog.Logger
+	hubAPI   *api.API
+	archives *chartrepo.ArchiveLoader
+	renderer *helm.Renderer
+	logger   zerolog.Logger
 }
 
 // NewHandlers creates a new Handlers instance.
-func NewHandlers(hubAPI *api.API) *Handlers {
+func NewHandlers(hubAPI *api.API, ag archive.Getter) *Handlers {
 	return &Handlers{
-		hubAPI: hubAPI,
-		logger: log.With().Str("handlers", "pkg").Logger(),
+		hubAPI:   hubAPI,
+		archives: chartrepo.NewArchiveLoader(ag, maxArchiveSize, maxArchiveFilesSize, archivesCacheSize),
+		renderer: helm.NewRenderer(maxConcurrentRenders, renderTimeout, maxRenderedSize),
+		logger:   log.With().Str("handlers", "pkg").Logger(),
 	}
 }
 
@@ -55,6 +62,26 @@ func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) {
 	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
 }
 
+// GetDependents is an http handler used to get the packages that depend on a
+// given chart.
+func (h *Handlers) GetDependents(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+	}
+	jsonData, err := h.hubAPI.Packages.GetDependentsJSON(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetDependents").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
+}
+
 // GetStats is an http handler used to get some stats about packages registered
 // in the hub database.
 func (h *Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
@@ -79,6 +106,70 @@ func (h *Handlers) GetUpdates(w http.ResponseWriter, r *http.Request) {
 	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
 }
 
+// GetValues is an http handler used to get the default values (values.yaml)
+// of a given chart version.
+func (h *Handlers) GetValues(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	yamlData, err := h.hubAPI.Packages.GetValuesYAML(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetValues").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderYAML(w, yamlData, helpers.DefaultAPICacheMaxAge)
+}
+
+// GetValuesDocs is an http handler used to get the documentation of the
+// parameters available in the default values (values.yaml) of a given chart
+// version.
+func (h *Handlers) GetValuesDocs(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	jsonData, err := h.hubAPI.Packages.GetValuesDocsJSON(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetValuesDocs").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
+}
+
+// GetValuesSchema is an http handler used to get the values json schema
+// (values.schema.json) of a given chart version.
+func (h *Handlers) GetValuesSchema(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	jsonData, err := h.hubAPI.Packages.GetValuesSchemaJSON(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "GetValuesSchema").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, jsonData, helpers.DefaultAPICacheMaxAge)
+}
+
 // Search is an http handler used to searchPackages for packages in the hub
 // database.
 func (h *Handlers) Search(w http.ResponseWriter, r *http.Request) {
@@ -161,6 +252,34 @@ func buildSearchInput(qs url.Values) (*pkg.SearchInput, error) {
 		}
 	}
 
+	// Only signed packages
+	var signed bool
+	if qs.Get("signed") != "" {
+		var err error
+		signed, err = strconv.ParseBool(qs.Get("signed"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed: %s", qs.Get("signed"))
+		}
+	}
+
+	// Containers images
+	images := qs["image"]
+	for _, image := range images {
+		if image == "" {
+			return nil, fmt.Errorf("invalid image: %s", image)
+		}
+	}
+
+	// Kubernetes version
+	var kubeVersion int
+	if qs.Get("kube_version") != "" {
+		var err error
+		kubeVersion, err = helm.EncodeKubeVersion(qs.Get("kube_version"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid kube_version: %s", qs.Get("kube_version"))
+		}
+	}
+
 	return &pkg.SearchInput{
 		Limit:             limit,
 		Offset:            offset,
@@ -169,5 +288,8 @@ func buildSearchInput(qs url.Values) (*pkg.SearchInput, error) {
 		PackageKinds:      kinds,
 		ChartRepositories: repos,
 		Deprecated:        deprecated,
+		Signed:            signed,
+		Images:            images,
+		KubeVersion:       kubeVersion,
 	}, nil
 }
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/helm"
+	"github.com/go-chi/chi"
+)
+
+const (
+	// maxConcurrentRenders represents the maximum number of charts that can
+	// be rendered concurrently.
+	maxConcurrentRenders = 4
+
+	// renderTimeout represents the maximum time a chart render can take. It
+	// is kept, along with the archive download timeout, well under the hub
+	// server write timeout, so that the response is always delivered.
+	renderTimeout = 2 * time.Second
+
+	// maxRenderedSize represents the maximum size of the manifests produced
+	// when rendering a chart.
+	maxRenderedSize = 5 * 1024 * 1024
+)
+
+// RenderTemplates is an http handler used to render the templates of a given
+// chart version using the values (yaml or json) provided in the request body,
+// which are merged with the chart's default values. No cluster is involved,
+// the manifests returned are the ones Helm would produce when installing it.
+func (h *Handlers) RenderTemplates(w http.ResponseWriter, r *http.Request) {
+	// Read values provided
+	values, err := ioutil.ReadAll(io.LimitReader(r.Body, maxValuesSize+1))
+	if err != nil {
+		http.Error(w, "error reading values", http.StatusBadRequest)
+		return
+	}
+	if len(values) > maxValuesSize {
+		http.Error(w, "values too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Load chart version archive and render its templates
+	version := chi.URLParam(r, "version")
+	files, ok := h.loadArchiveFiles(w, r, version, "RenderTemplates")
+	if !ok {
+		return
+	}
+	rc, err := h.renderer.Render(files, values)
+	if err != nil {
+		switch {
+		case errors.Is(err, helm.ErrInvalidValues):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, helm.ErrRendererBusy):
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		case errors.Is(err, helm.ErrRenderTimeout):
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		default:
+			h.logger.Debug().Err(err).Str("version", version).Str("method", "RenderTemplates").Send()
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		}
+		return
+	}
+	jsonData, _ := json.Marshal(rc)
+	helpers.RenderJSON(w, jsonData, 0)
+}
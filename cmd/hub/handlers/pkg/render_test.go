@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/helm"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	getContentDBQuery := "select get_package_content($1::jsonb)"
+	getRepoDBQuery := "select get_chart_repository_by_name($1::text)"
+	s := newChartArchiveServer(t)
+	defer s.Close()
+
+	t.Run("values too large", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		body := strings.NewReader(strings.Repeat("a", maxValuesSize+1))
+		r, _ := http.NewRequest("POST", "/", body)
+		hw.h.RenderTemplates(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("non existing package version", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(""))
+		hw.h.RenderTemplates(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(""))
+		hw.h.RenderTemplates(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("key: [value"))
+		hw.h.RenderTemplates(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("too many renders in progress", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+		hw.h.renderer = helm.NewRenderer(0, renderTimeout, maxRenderedSize)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(""))
+		hw.h.RenderTemplates(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, "5", resp.Header.Get("Retry-After"))
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("render timed out", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-2.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		// Serve the handler with the same write timeout used by the hub server
+		// to check that the response is delivered
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(hw.h.RenderTemplates))
+		srv.Config.WriteTimeout = 5 * time.Second
+		srv.Start()
+		defer srv.Close()
+		resp, err := http.Post(srv.URL, "application/yaml", strings.NewReader(""))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+		assert.Equal(t, helm.ErrRenderTimeout.Error()+"\n", string(data))
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("templates rendered", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getContentDBQuery, mock.Anything).Return(contentJSON(s.URL+"/chart-1.0.0.tgz"), nil)
+		hw.db.On("QueryRow", getRepoDBQuery, mock.Anything).Return(repoJSON(s.URL), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader(`{"key": "value"}`))
+		hw.h.RenderTemplates(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.JSONEq(t, `{
+			"manifests": [
+				{
+					"name": "chart/templates/configmap.yaml",
+					"content": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: release-name\n"
+				}
+			],
+			"notes": "Thanks for installing chart\n"
+		}`, string(data))
+		hw.db.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
+	"github.com/artifacthub/hub/internal/helm"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v4"
+)
+
+// maxValuesSize represents the maximum size of the values documents that can
+// be validated.
+const maxValuesSize = 1 * 1024 * 1024
+
+// ValidateValues is an http handler used to validate the values (yaml or
+// json) provided in the request body against the values json schema of a
+// given chart version, once merged with its default values.
+func (h *Handlers) ValidateValues(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+
+	// Read values provided
+	values, err := ioutil.ReadAll(io.LimitReader(r.Body, maxValuesSize+1))
+	if err != nil {
+		http.Error(w, "error reading values", http.StatusBadRequest)
+		return
+	}
+	if len(values) > maxValuesSize {
+		http.Error(w, "values too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Get chart version values schema and default values
+	schema, err := h.hubAPI.Packages.GetValuesSchemaJSON(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.NotFound(w, r)
+		} else {
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "ValidateValues").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	defaults, err := h.hubAPI.Packages.GetValuesYAML(r.Context(), input)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "ValidateValues").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	// Validate values
+	validation, err := helm.ValidateValues(values, defaults, schema)
+	if err != nil {
+		switch {
+		case errors.Is(err, helm.ErrInvalidValues):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, helm.ErrInvalidValuesSchema):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			h.logger.Error().Err(err).Interface("input", input).Str("method", "ValidateValues").Send()
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	jsonData, _ := json.Marshal(validation)
+	helpers.RenderJSON(w, jsonData, 0)
+}
@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateValues(t *testing.T) {
+	getSchemaDBQuery := "select get_package_values_schema($1::jsonb)"
+	getValuesDBQuery := "select get_package_values($1::jsonb)"
+	schema := []byte(`{
+		"type": "object",
+		"required": ["replicaCount"],
+		"properties": {
+			"replicaCount": {"type": "integer"}
+		}
+	}`)
+
+	t.Run("values too large", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		body := strings.NewReader(strings.Repeat("a", maxValuesSize+1))
+		r, _ := http.NewRequest("POST", "/", body)
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("non existing values schema", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSchemaDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("replicaCount: 1"))
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting values schema", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSchemaDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("replicaCount: 1"))
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("database error getting default values", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSchemaDBQuery, mock.Anything).Return(schema, nil)
+		hw.db.On("QueryRow", getValuesDBQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("replicaCount: 1"))
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSchemaDBQuery, mock.Anything).Return(schema, nil)
+		hw.db.On("QueryRow", getValuesDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("key: [value"))
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid values schema", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", getSchemaDBQuery, mock.Anything).Return([]byte("{"), nil)
+		hw.db.On("QueryRow", getValuesDBQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", strings.NewReader("replicaCount: 1"))
+		hw.h.ValidateValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("values validated", func(t *testing.T) {
+		testCases := []struct {
+			values         string
+			expectedResult string
+		}{
+			{
+				"",
+				`{"valid":true,"errors":[]}`,
+			},
+			{
+				`{"replicaCount": 2}`,
+				`{"valid":true,"errors":[]}`,
+			},
+			{
+				"replicaCount: two",
+				`{"valid":false,"errors":[{"path":"replicaCount","message":"Invalid type. Expected: integer, given: string"}]}`,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.values, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.db.On("QueryRow", getSchemaDBQuery, mock.Anything).Return(schema, nil)
+				hw.db.On("QueryRow", getValuesDBQuery, mock.Anything).Return([]byte("replicaCount: 1"), nil)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("POST", "/", strings.NewReader(tc.values))
+				hw.h.ValidateValues(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+				h := resp.Header
+				data, _ := ioutil.ReadAll(resp.Body)
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", h.Get("Content-Type"))
+				assert.Equal(t, tests.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+				assert.Equal(t, tc.expectedResult, string(data))
+				hw.db.AssertExpectations(t)
+			})
+		}
+	})
+}
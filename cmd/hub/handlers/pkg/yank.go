@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/go-chi/chi"
+)
+
+// Yank is an http handler used to mark a package version as yanked. Yanked
+// versions are still available, but they are not used as the package's latest
+// version. The reason why the version has been yanked must be provided in the
+// request body.
+func (h *Handlers) Yank(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.YankInput{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Reason == "" {
+		http.Error(w, "a reason for yanking the version must be provided", http.StatusBadRequest)
+		return
+	}
+	input.ChartRepositoryName = chi.URLParam(r, "repoName")
+	input.PackageName = chi.URLParam(r, "packageName")
+	input.Version = chi.URLParam(r, "version")
+	if err := h.hubAPI.Packages.Yank(r.Context(), input); err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "Yank").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Unyank is an http handler used to revert the yanking of a package version.
+func (h *Handlers) Unyank(w http.ResponseWriter, r *http.Request) {
+	input := &pkg.GetInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	if err := h.hubAPI.Packages.Unyank(r.Context(), input); err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "Unyank").Send()
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
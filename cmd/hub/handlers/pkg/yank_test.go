@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYank(t *testing.T) {
+	dbQuery := "select yank_package_version($1::uuid, $2::jsonb)"
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			body        string
+		}{
+			{"invalid json body", "{"},
+			{"reason not provided", `{"reason": ""}`},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.description, func(t *testing.T) {
+				hw := newHandlersWrapper()
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("PUT", "/", strings.NewReader(tc.body))
+				r = withPackageVersion(r)
+				hw.h.Yank(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, "userID", yankInputJSON).Return(tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("PUT", "/", strings.NewReader(`{"reason": "broken release"}`))
+		r = withPackageVersion(r)
+		hw.h.Yank(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("package version yanked", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, "userID", yankInputJSON).Return(nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("PUT", "/", strings.NewReader(`{"reason": "broken release"}`))
+		r = withPackageVersion(r)
+		hw.h.Yank(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+func TestUnyank(t *testing.T) {
+	dbQuery := "select unyank_package_version($1::uuid, $2::jsonb)"
+	inputJSON := []byte(`{"chart_repository_name":"repo1","package_name":"pkg1","version":"1.0.0"}`)
+
+	t.Run("database error", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, "userID", inputJSON).Return(tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/", nil)
+		r = withPackageVersion(r)
+		hw.h.Unyank(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("package version unyanked", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("Exec", dbQuery, "userID", inputJSON).Return(nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("DELETE", "/", nil)
+		r = withPackageVersion(r)
+		hw.h.Unyank(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+}
+
+var yankInputJSON = []byte(`{"chart_repository_name":"repo1","package_name":"pkg1","version":"1.0.0","reason":"broken release"}`)
+
+func withPackageVersion(r *http.Request) *http.Request {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName", "packageName", "version"},
+			Values: []string{"repo1", "pkg1", "1.0.0"},
+		},
+	}
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	return r.WithContext(context.WithValue(ctx, hub.UserIDKey, "userID"))
+}
@@ -236,6 +236,38 @@ func (h *Handlers) RequireLogin(next http.Handler) http.Handler {
 	})
 }
 
+// RequireLoginOrBasicAuth is a middleware that verifies if a user is logged
+// in, as RequireLogin does, or if the hub credentials (email and password)
+// provided using http basic authentication are valid. It allows tools that
+// cannot log in, like Helm plugins, to use some endpoints.
+func (h *Handlers) RequireLoginOrBasicAuth(next http.Handler) http.Handler {
+	requireLogin := h.RequireLogin(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, password, ok := r.BasicAuth()
+		if !ok {
+			requireLogin.ServeHTTP(w, r)
+			return
+		}
+
+		// Check if the credentials provided are valid
+		checkCredentialsOutput, err := h.hubAPI.User.CheckCredentials(r.Context(), email, password)
+		if err != nil {
+			h.logger.Error().Err(err).Str("method", "RequireLoginOrBasicAuth").Msg("checkCredentials failed")
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		if !checkCredentialsOutput.Valid {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Hub"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// Inject userID in context and call next handler
+		ctx := context.WithValue(r.Context(), hub.UserIDKey, checkCredentialsOutput.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // VerifyEmail is an http handler used to verify a user's email address.
 func (h *Handlers) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	code := r.FormValue("code")
@@ -464,6 +464,75 @@ func TestRequireLogin(t *testing.T) {
 	})
 }
 
+func TestRequireLoginOrBasicAuth(t *testing.T) {
+	dbQuery := `select user_id, password from "user" where email = $1`
+
+	t.Run("no credentials nor session cookie provided", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.RequireLoginOrBasicAuth(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("error checking credentials", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.db.On("QueryRow", dbQuery, "email").Return(nil, tests.ErrFakeDatabaseFailure)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.SetBasicAuth("email", "pass")
+		hw.h.RequireLoginOrBasicAuth(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("invalid credentials provided", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		pw, _ := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.DefaultCost)
+		hw.db.On("QueryRow", dbQuery, "email").Return([]interface{}{"userID", string(pw)}, nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.SetBasicAuth("email", "pass2")
+		hw.h.RequireLoginOrBasicAuth(http.HandlerFunc(testsOK)).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, `Basic realm="Hub"`, resp.Header.Get("WWW-Authenticate"))
+		hw.db.AssertExpectations(t)
+	})
+
+	t.Run("valid credentials provided", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		pw, _ := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.DefaultCost)
+		hw.db.On("QueryRow", dbQuery, "email").Return([]interface{}{"userID", string(pw)}, nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.SetBasicAuth("email", "pass")
+		var userID interface{}
+		next := func(w http.ResponseWriter, r *http.Request) {
+			userID = r.Context().Value(hub.UserIDKey)
+		}
+		hw.h.RequireLoginOrBasicAuth(http.HandlerFunc(next)).ServeHTTP(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "userID", userID)
+		hw.db.AssertExpectations(t)
+	})
+}
+
 func TestVerifyEmail(t *testing.T) {
 	dbQuery := "select verify_email($1::uuid)"
 
@@ -534,7 +603,7 @@ func newHandlersWrapper() *handlersWrapper {
 	cfg := viper.New()
 	db := &tests.DBMock{}
 	es := &tests.EmailSenderMock{}
-	hubAPI := api.New(db, es)
+	hubAPI := api.New(db, es, nil)
 
 	return &handlersWrapper{
 		cfg: cfg,
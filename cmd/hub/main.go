@@ -10,7 +10,10 @@ import (
 
 	"github.com/artifacthub/hub/cmd/hub/handlers"
 	"github.com/artifacthub/hub/internal/api"
+	archivepg "github.com/artifacthub/hub/internal/archive/pg"
 	"github.com/artifacthub/hub/internal/email"
+	"github.com/artifacthub/hub/internal/encryption"
+	"github.com/artifacthub/hub/internal/helm"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/img/pg"
 	"github.com/artifacthub/hub/internal/util"
@@ -18,6 +21,9 @@ import (
 )
 
 func main() {
+	// Run as a charts render process when started by a renderer
+	helm.HandleRenderProcess()
+
 	// Setup configuration and logger
 	cfg, err := util.SetupConfig("hub")
 	if err != nil {
@@ -28,7 +34,7 @@ func main() {
 		log.Fatal().Err(err).Msg("Logger setup failed")
 	}
 
-	// Setup hub api and image store instances
+	// Setup hub api, image store and archive store instances
 	db, err := util.SetupDB(cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Database setup failed")
@@ -37,8 +43,17 @@ func main() {
 	if s := email.NewSender(cfg); s != nil {
 		es = s
 	}
-	hubAPI := api.New(db, es)
+	var enc hub.Encrypter
+	e, err := encryption.NewEncrypter(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Encrypter setup failed")
+	}
+	if e != nil {
+		enc = e
+	}
+	hubAPI := api.New(db, es, enc)
 	imageStore := pg.NewImageStore(db)
+	archiveStore := archivepg.NewArchiveStore(db)
 
 	// Setup and launch server
 	addr := cfg.GetString("server.addr")
@@ -47,7 +62,7 @@ func main() {
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 5 * time.Second,
 		IdleTimeout:  1 * time.Minute,
-		Handler:      handlers.Setup(cfg, hubAPI, imageStore).Router,
+		Handler:      handlers.Setup(cfg, hubAPI, imageStore, archiveStore).Router,
 	}
 	go func() {
 		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
@@ -13,24 +13,47 @@
 {{ template "users/verify_email.sql" }}
 
 {{ template "packages/get_package.sql" }}
+{{ template "packages/get_package_content.sql" }}
+{{ template "packages/get_package_dependents.sql" }}
+{{ template "packages/get_package_values.sql" }}
+{{ template "packages/get_package_values_docs.sql" }}
+{{ template "packages/get_package_values_schema.sql" }}
 {{ template "packages/get_packages_stats.sql" }}
 {{ template "packages/get_packages_updates.sql" }}
 {{ template "packages/register_package.sql" }}
 {{ template "packages/search_packages.sql" }}
 {{ template "packages/semver_gte.sql" }}
+{{ template "packages/unregister_package.sql" }}
+{{ template "packages/unyank_package_version.sql" }}
+{{ template "packages/update_package_latest_version.sql" }}
+{{ template "packages/yank_package_version.sql" }}
 
 {{ template "chart_repositories/add_chart_repository.sql" }}
+{{ template "chart_repositories/add_tracking_request.sql" }}
 {{ template "chart_repositories/delete_chart_repository.sql" }}
+{{ template "chart_repositories/delete_chart_repository_webhook_secret.sql" }}
 {{ template "chart_repositories/get_chart_repositories.sql" }}
+{{ template "chart_repositories/get_chart_repositories_due_for_tracking.sql" }}
 {{ template "chart_repositories/get_chart_repository_by_name.sql" }}
 {{ template "chart_repositories/get_chart_repository_packages_digest.sql" }}
+{{ template "chart_repositories/get_chart_repository_tracking_request.sql" }}
+{{ template "chart_repositories/get_chart_repository_tracking_run_errors.sql" }}
+{{ template "chart_repositories/get_chart_repository_tracking_runs.sql" }}
+{{ template "chart_repositories/get_mirror_index_entries.sql" }}
 {{ template "chart_repositories/get_org_chart_repositories.sql" }}
 {{ template "chart_repositories/get_user_chart_repositories.sql" }}
+{{ template "chart_repositories/register_tracking_run.sql" }}
+{{ template "chart_repositories/request_chart_repository_tracking.sql" }}
+{{ template "chart_repositories/set_chart_repository_webhook_secret.sql" }}
 {{ template "chart_repositories/update_chart_repository.sql" }}
+{{ template "chart_repositories/user_has_access_to_chart_repository.sql" }}
 
 {{ template "images/get_image.sql" }}
 {{ template "images/register_image.sql" }}
 
+{{ template "archives/get_chart_archive.sql" }}
+{{ template "archives/register_chart_archive.sql" }}
+
 ---- create above / drop below ----
 
 -- Nothing to do
@@ -0,0 +1,8 @@
+-- get_chart_archive returns the chart archive identified by the digest
+-- provided.
+create or replace function get_chart_archive(p_digest text)
+returns setof bytea as $$
+    select data
+    from chart_archive
+    where digest = p_digest;
+$$ language sql;
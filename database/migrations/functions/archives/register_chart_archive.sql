@@ -0,0 +1,9 @@
+-- register_chart_archive registers the provided chart archive in the database.
+-- Archives are identified by their digest, so archives already registered are
+-- ignored.
+create or replace function register_chart_archive(p_digest text, p_data bytea)
+returns void as $$
+    insert into chart_archive (digest, data)
+    values (p_digest, p_data)
+    on conflict do nothing;
+$$ language sql;
@@ -23,12 +23,22 @@ begin
         name,
         display_name,
         url,
+        credentials,
+        keyring,
+        tracking_interval,
+        tracking_jitter,
+        hosted,
         user_id,
         organization_id
     ) values (
         p_chart_repository->>'name',
         nullif(p_chart_repository->>'display_name', ''),
         p_chart_repository->>'url',
+        nullif(p_chart_repository->>'credentials', ''),
+        nullif(p_chart_repository->>'keyring', ''),
+        coalesce((p_chart_repository->>'tracking_interval')::int, 30),
+        coalesce((p_chart_repository->>'tracking_jitter')::int, 5),
+        coalesce((p_chart_repository->>'hosted')::boolean, false),
         v_owner_user_id,
         v_owner_organization_id
     );
@@ -0,0 +1,31 @@
+-- add_tracking_request registers a tracking request for the provided chart
+-- repository, returning its id. If the chart repository has a tracking request
+-- pending already, the id of that one is returned instead. Hosted chart
+-- repositories cannot be tracked, so requests for them are rejected.
+create or replace function add_tracking_request(p_chart_repository_id uuid)
+returns uuid as $$
+declare
+    v_tracking_request_id uuid;
+begin
+    if exists (
+        select chart_repository_id
+        from chart_repository
+        where chart_repository_id = p_chart_repository_id
+        and hosted = true
+    ) then
+        raise 'hosted chart repositories cannot be tracked'
+        using errcode = 'object_not_in_prerequisite_state';
+    end if;
+
+    select tracking_request_id into v_tracking_request_id
+    from tracking_request
+    where chart_repository_id = p_chart_repository_id
+    and status = 'pending';
+    if not found then
+        insert into tracking_request (chart_repository_id)
+        values (p_chart_repository_id)
+        returning tracking_request_id into v_tracking_request_id;
+    end if;
+    return v_tracking_request_id;
+end
+$$ language plpgsql;
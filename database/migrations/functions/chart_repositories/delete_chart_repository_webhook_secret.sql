@@ -0,0 +1,14 @@
+-- delete_chart_repository_webhook_secret deletes the webhook secret of the
+-- provided chart repository, disabling its webhook. The user provided must own
+-- the chart repository or belong to the organization which owns it.
+create or replace function delete_chart_repository_webhook_secret(p_user_id uuid, p_chart_repository_name text)
+returns void as $$
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_chart_repository_name) then
+        raise insufficient_privilege;
+    end if;
+
+    update chart_repository set webhook_secret = null
+    where name = p_chart_repository_name;
+end
+$$ language plpgsql;
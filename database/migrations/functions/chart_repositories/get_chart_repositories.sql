@@ -6,7 +6,13 @@ returns setof json as $$
         'chart_repository_id', chart_repository_id,
         'name', name,
         'display_name', display_name,
-        'url', url
+        'url', url,
+        'credentials', credentials,
+        'tracking_interval', tracking_interval,
+        'tracking_jitter', tracking_jitter,
+        'keyring', keyring,
+        'hosted', hosted,
+        'index_cache', index_cache
     )), '[]')
     from chart_repository;
 $$ language sql;
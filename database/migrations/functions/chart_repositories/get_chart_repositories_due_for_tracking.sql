@@ -0,0 +1,31 @@
+-- get_chart_repositories_due_for_tracking returns the chart repositories that
+-- have never been tracked, whose next tracking is due or that have a tracking
+-- request pending as a json array. Hosted chart repositories are not tracked,
+-- so they are never due.
+create or replace function get_chart_repositories_due_for_tracking()
+returns setof json as $$
+    select coalesce(json_agg(json_build_object(
+        'chart_repository_id', chart_repository_id,
+        'name', name,
+        'display_name', display_name,
+        'url', url,
+        'credentials', credentials,
+        'tracking_interval', tracking_interval,
+        'tracking_jitter', tracking_jitter,
+        'keyring', keyring,
+        'hosted', hosted,
+        'index_cache', index_cache
+    )), '[]')
+    from chart_repository
+    where hosted = false
+    and (
+        next_tracking_ts is null
+        or next_tracking_ts <= current_timestamp
+        or exists (
+            select tracking_request_id
+            from tracking_request tq
+            where tq.chart_repository_id = chart_repository.chart_repository_id
+            and tq.status = 'pending'
+        )
+    );
+$$ language sql;
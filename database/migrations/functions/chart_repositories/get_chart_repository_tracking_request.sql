@@ -0,0 +1,27 @@
+-- get_chart_repository_tracking_request returns the tracking request provided
+-- of the given chart repository as a json object. The user provided must own
+-- the chart repository or belong to the organization which owns it.
+create or replace function get_chart_repository_tracking_request(
+    p_user_id uuid,
+    p_chart_repository_name text,
+    p_tracking_request_id uuid
+) returns setof json as $$
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_chart_repository_name) then
+        raise insufficient_privilege;
+    end if;
+
+    return query
+    select json_build_object(
+        'tracking_request_id', tq.tracking_request_id,
+        'status', tq.status,
+        'created_at', floor(extract(epoch from tq.created_at)),
+        'completed_at', floor(extract(epoch from tq.completed_at)),
+        'tracking_run_id', tq.tracking_run_id
+    )
+    from tracking_request tq
+    join chart_repository cr using (chart_repository_id)
+    where cr.name = p_chart_repository_name
+    and tq.tracking_request_id = p_tracking_request_id;
+end
+$$ language plpgsql;
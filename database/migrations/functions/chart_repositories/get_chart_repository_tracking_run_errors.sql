@@ -0,0 +1,29 @@
+-- get_chart_repository_tracking_run_errors returns the errors found during the
+-- provided tracking run of the chart repository given as a json array. The
+-- user provided must own the chart repository or belong to the organization
+-- which owns it.
+create or replace function get_chart_repository_tracking_run_errors(
+    p_user_id uuid,
+    p_chart_repository_name text,
+    p_tracking_run_id uuid
+) returns setof json as $$
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_chart_repository_name) then
+        raise insufficient_privilege;
+    end if;
+
+    return query
+    select coalesce(json_agg(json_build_object(
+        'kind', te.kind,
+        'chart_name', te.chart_name,
+        'chart_version', te.chart_version,
+        'url', te.url,
+        'message', te.message
+    ) order by te.chart_name, te.chart_version), '[]')
+    from tracking_error te
+    join tracking_run tr using (tracking_run_id)
+    join chart_repository cr using (chart_repository_id)
+    where cr.name = p_chart_repository_name
+    and te.tracking_run_id = p_tracking_run_id;
+end
+$$ language plpgsql;
@@ -0,0 +1,28 @@
+-- get_chart_repository_tracking_runs returns the most recent tracking runs of
+-- the provided chart repository as a json array. The user provided must own
+-- the chart repository or belong to the organization which owns it.
+create or replace function get_chart_repository_tracking_runs(p_user_id uuid, p_chart_repository_name text)
+returns setof json as $$
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_chart_repository_name) then
+        raise insufficient_privilege;
+    end if;
+
+    return query
+    select coalesce(json_agg(json_build_object(
+        'tracking_run_id', tr.tracking_run_id,
+        'started_at', floor(extract(epoch from tr.started_at)),
+        'finished_at', floor(extract(epoch from tr.finished_at)),
+        'processed', tr.processed,
+        'registered', tr.registered,
+        'failed', tr.failed,
+        'errors_count', (
+            select count(*) from tracking_error te
+            where te.tracking_run_id = tr.tracking_run_id
+        )
+    ) order by tr.started_at desc), '[]')
+    from tracking_run tr
+    join chart_repository cr using (chart_repository_id)
+    where cr.name = p_chart_repository_name;
+end
+$$ language plpgsql;
@@ -0,0 +1,22 @@
+-- get_mirror_index_entries returns the charts versions whose archive is
+-- available in the archive mirror as a json array. Only the entries of the
+-- chart repository identified by the name provided are returned, unless no
+-- name is provided. No rows are returned if the repository does not exist.
+create or replace function get_mirror_index_entries(p_chart_repository_name text)
+returns setof json as $$
+    select coalesce(json_agg(json_build_object(
+        'chart_repository_name', r.name,
+        'package_name', p.normalized_name,
+        'metadata', s.chart_metadata,
+        'digest', s.archive_digest
+    ) order by r.name, p.normalized_name, s.version), '[]')
+    from package p
+    join snapshot s using (package_id)
+    join chart_repository r using (chart_repository_id)
+    where s.archive_digest is not null
+    and s.chart_metadata is not null
+    and (p_chart_repository_name is null or r.name = p_chart_repository_name)
+    having p_chart_repository_name is null or exists (
+        select 1 from chart_repository where name = p_chart_repository_name
+    );
+$$ language sql;
@@ -8,6 +8,11 @@ returns setof json as $$
         'name', cr.name,
         'display_name', cr.display_name,
         'url', cr.url,
+        'tracking_interval', cr.tracking_interval,
+        'tracking_jitter', cr.tracking_jitter,
+        'keyring', cr.keyring,
+        'hosted', cr.hosted,
+        'next_tracking_ts', floor(extract(epoch from cr.next_tracking_ts)),
         'last_tracking_ts', floor(extract(epoch from cr.last_tracking_ts)),
         'last_tracking_errors', cr.last_tracking_errors
     )), '[]')
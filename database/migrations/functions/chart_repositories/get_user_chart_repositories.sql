@@ -7,6 +7,11 @@ returns setof json as $$
         'name', name,
         'display_name', display_name,
         'url', url,
+        'tracking_interval', tracking_interval,
+        'tracking_jitter', tracking_jitter,
+        'keyring', keyring,
+        'hosted', hosted,
+        'next_tracking_ts', floor(extract(epoch from next_tracking_ts)),
         'last_tracking_ts', floor(extract(epoch from last_tracking_ts)),
         'last_tracking_errors', last_tracking_errors
     )), '[]')
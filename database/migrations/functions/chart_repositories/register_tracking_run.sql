@@ -0,0 +1,74 @@
+-- register_tracking_run registers the provided chart repository tracking run
+-- in the database, including the errors found during the tracking, and marks
+-- as completed the tracking requests it handled. Only the most recent runs of
+-- each chart repository are kept, as well as the completed tracking requests
+-- that belong to them.
+create or replace function register_tracking_run(p_tracking_run jsonb)
+returns void as $$
+declare
+    v_tracking_run_id uuid;
+    v_chart_repository_id uuid := p_tracking_run->>'chart_repository_id';
+begin
+    -- Register tracking run
+    insert into tracking_run (
+        chart_repository_id,
+        started_at,
+        finished_at,
+        processed,
+        registered,
+        failed
+    ) values (
+        v_chart_repository_id,
+        to_timestamp((p_tracking_run->>'started_at')::bigint),
+        to_timestamp((p_tracking_run->>'finished_at')::bigint),
+        coalesce((p_tracking_run->>'processed')::int, 0),
+        coalesce((p_tracking_run->>'registered')::int, 0),
+        coalesce((p_tracking_run->>'failed')::int, 0)
+    )
+    returning tracking_run_id into v_tracking_run_id;
+
+    -- Register tracking errors
+    insert into tracking_error (
+        tracking_run_id,
+        kind,
+        chart_name,
+        chart_version,
+        url,
+        message
+    )
+    select
+        v_tracking_run_id,
+        e->>'kind',
+        nullif(e->>'chart_name', ''),
+        nullif(e->>'chart_version', ''),
+        nullif(e->>'url', ''),
+        e->>'message'
+    from jsonb_array_elements(nullif(p_tracking_run->'errors', 'null'::jsonb)) as e;
+
+    -- Complete tracking requests pending when the tracking run started
+    update tracking_request set
+        status = 'completed',
+        completed_at = current_timestamp,
+        tracking_run_id = v_tracking_run_id
+    where chart_repository_id = v_chart_repository_id
+    and status = 'pending'
+    and created_at <= to_timestamp((p_tracking_run->>'started_at')::bigint);
+
+    -- Delete old tracking runs
+    delete from tracking_run
+    where chart_repository_id = v_chart_repository_id
+    and tracking_run_id not in (
+        select tracking_run_id
+        from tracking_run
+        where chart_repository_id = v_chart_repository_id
+        order by started_at desc
+        limit 25
+    );
+
+    -- Delete completed tracking requests whose tracking run has been deleted
+    delete from tracking_request
+    where chart_repository_id = v_chart_repository_id
+    and status = 'completed'
+    and tracking_run_id is null;
+end
+$$ language plpgsql;
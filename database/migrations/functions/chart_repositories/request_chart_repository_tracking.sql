@@ -0,0 +1,26 @@
+-- request_chart_repository_tracking requests the tracking of the provided
+-- chart repository as soon as possible, returning the tracking request as a
+-- json object. If the chart repository has a tracking request pending already,
+-- that one is returned instead. The user provided must own the chart
+-- repository or belong to the organization which owns it.
+create or replace function request_chart_repository_tracking(p_user_id uuid, p_chart_repository_name text)
+returns setof json as $$
+declare
+    v_tracking_request_id uuid;
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_chart_repository_name) then
+        raise insufficient_privilege;
+    end if;
+
+    select add_tracking_request(chart_repository_id) into v_tracking_request_id
+    from chart_repository
+    where name = p_chart_repository_name;
+
+    return query
+    select get_chart_repository_tracking_request(
+        p_user_id,
+        p_chart_repository_name,
+        v_tracking_request_id
+    );
+end
+$$ language plpgsql;
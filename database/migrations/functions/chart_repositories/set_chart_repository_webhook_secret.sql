@@ -0,0 +1,37 @@
+-- set_chart_repository_webhook_secret sets the webhook secret of the provided
+-- chart repository, replacing the existing one if any, and returns the chart
+-- repository id. The secret is generated and encrypted by the hub before
+-- calling this function. The user provided must own the chart repository or
+-- belong to the organization which owns it. Hosted chart repositories cannot
+-- be tracked, so webhooks cannot be enabled for them.
+create or replace function set_chart_repository_webhook_secret(
+    p_user_id uuid,
+    p_chart_repository_name text,
+    p_webhook_secret text
+)
+returns uuid as $$
+declare
+    v_chart_repository_id uuid;
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_chart_repository_name) then
+        raise insufficient_privilege;
+    end if;
+
+    if exists (
+        select chart_repository_id
+        from chart_repository
+        where name = p_chart_repository_name
+        and hosted = true
+    ) then
+        raise 'hosted chart repositories cannot be tracked'
+        using errcode = 'object_not_in_prerequisite_state';
+    end if;
+
+    update chart_repository set
+        webhook_secret = p_webhook_secret
+    where name = p_chart_repository_name
+    returning chart_repository_id into v_chart_repository_id;
+
+    return v_chart_repository_id;
+end
+$$ language plpgsql;
@@ -22,9 +22,30 @@ begin
         raise insufficient_privilege;
     end if;
 
+    -- Credentials, keyring and tracking schedule settings are only updated
+    -- when provided (an empty credentials or keyring value removes them).
+    -- The index cache is reset when the url changes. The url of hosted
+    -- repositories is set by the hub, so it cannot be updated.
     update chart_repository set
         display_name = nullif(p_chart_repository->>'display_name', ''),
-        url = p_chart_repository->>'url'
+        url = case when hosted then url else p_chart_repository->>'url' end,
+        index_cache = case when hosted or url = p_chart_repository->>'url' then
+            index_cache
+        else
+            null
+        end,
+        tracking_interval = coalesce((p_chart_repository->>'tracking_interval')::int, tracking_interval),
+        tracking_jitter = coalesce((p_chart_repository->>'tracking_jitter')::int, tracking_jitter),
+        credentials = case when p_chart_repository ? 'credentials' then
+            nullif(p_chart_repository->>'credentials', '')
+        else
+            credentials
+        end,
+        keyring = case when p_chart_repository ? 'keyring' then
+            nullif(p_chart_repository->>'keyring', '')
+        else
+            keyring
+        end
     where name = p_chart_repository->>'name';
 end
 $$ language plpgsql;
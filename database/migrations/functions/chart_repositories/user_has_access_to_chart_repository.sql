@@ -0,0 +1,15 @@
+-- user_has_access_to_chart_repository checks if a user owns the provided chart
+-- repository or belongs to the organization which owns it.
+create or replace function user_has_access_to_chart_repository(p_user_id uuid, p_chart_repository_name text)
+returns boolean as $$
+    select exists (
+        select chart_repository_id
+        from chart_repository cr
+        left join organization o using (organization_id)
+        where cr.name = p_chart_repository_name
+        and (
+            cr.user_id = p_user_id
+            or user_belongs_to_organization(p_user_id, o.name)
+        )
+    );
+$$ language sql;
@@ -41,12 +41,52 @@ begin
         'data', s.data,
         'version', s.version,
         'available_versions', (
-            select json_agg(distinct(version))
+            select json_agg(json_build_object(
+                'version', version,
+                'yanked', yanked
+            ) order by version)
             from snapshot
             where package_id = v_package_id
         ),
+        'yanked', s.yanked,
+        'yanked_reason', s.yanked_reason,
         'app_version', s.app_version,
         'digest', s.digest,
+        'signed', s.signed,
+        'signature_verified', s.signature_verified,
+        'signer', s.signer,
+        'dependencies', (
+            select json_agg(json_build_object(
+                'name', d->>'name',
+                'version', d->>'version',
+                'repository', d->>'repository',
+                'condition', d->>'condition',
+                'alias', d->>'alias',
+                'package', (
+                    select json_build_object(
+                        'package_id', dp.package_id,
+                        'name', dp.name,
+                        'normalized_name', dp.normalized_name,
+                        'chart_repository', json_build_object(
+                            'name', dr.name,
+                            'display_name', dr.display_name
+                        )
+                    )
+                    from package dp
+                    join chart_repository dr using (chart_repository_id)
+                    where dp.name = d->>'name'
+                    and rtrim(dr.url, '/') = rtrim(d->>'repository', '/')
+                    order by dr.name asc, dp.package_id asc
+                    limit 1
+                )
+            ))
+            from jsonb_array_elements(s.dependencies) d
+        ),
+        'container_images', s.container_images,
+        'kube_version', s.kube_version,
+        'deprecated_apis', s.deprecated_apis,
+        'lint_report', s.lint_report,
+        'quality_score', s.quality_score,
         'maintainers', (
             select json_agg(json_build_object(
                 'name', m.name,
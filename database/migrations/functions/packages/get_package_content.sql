@@ -0,0 +1,19 @@
+-- get_package_content returns the information needed to fetch the content
+-- (chart archive) of the package version identified by the input provided.
+create or replace function get_package_content(p_input jsonb)
+returns setof json as $$
+    select json_build_object(
+        'chart_repository_name', r.name,
+        'url', s.content_url,
+        'digest', s.digest,
+        'archive_digest', s.archive_digest,
+        'provenance_digest', s.provenance_digest
+    )
+    from package p
+    join snapshot s using (package_id)
+    join chart_repository r using (chart_repository_id)
+    where r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name'
+    and s.version = p_input->>'version'
+    and s.content_url is not null;
+$$ language sql;
@@ -0,0 +1,55 @@
+-- get_package_dependents returns the packages whose latest version depends on
+-- the chart identified by the input provided as a json array. A dependency
+-- matches the chart when its name and repository url match the chart ones.
+create or replace function get_package_dependents(p_input jsonb)
+returns setof json as $$
+declare
+    v_package_name text;
+    v_chart_repository_url text;
+begin
+    select p.name, r.url into v_package_name, v_chart_repository_url
+    from package p
+    join chart_repository r using (chart_repository_id)
+    where r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name';
+    if not found then
+        return;
+    end if;
+
+    return query
+    select coalesce(json_agg(json_build_object(
+        'package_id', package_id,
+        'kind', package_kind_id,
+        'name', name,
+        'normalized_name', normalized_name,
+        'display_name', display_name,
+        'logo_image_id', logo_image_id,
+        'version', version,
+        'dependency_version', dependency_version,
+        'chart_repository', json_build_object(
+            'name', chart_repository_name,
+            'display_name', chart_repository_display_name
+        )
+    ) order by name asc, chart_repository_name asc), '[]')
+    from (
+        select distinct on (p.package_id)
+            p.package_id,
+            p.package_kind_id,
+            p.name,
+            p.normalized_name,
+            p.display_name,
+            p.logo_image_id,
+            s.version,
+            d->>'version' as dependency_version,
+            r.name as chart_repository_name,
+            r.display_name as chart_repository_display_name
+        from package p
+        join snapshot s on s.package_id = p.package_id and s.version = p.latest_version
+        join chart_repository r on r.chart_repository_id = p.chart_repository_id
+        cross join lateral jsonb_array_elements(s.dependencies) d
+        where s.dependencies @> jsonb_build_array(jsonb_build_object('name', v_package_name))
+        and d->>'name' = v_package_name
+        and rtrim(d->>'repository', '/') = rtrim(v_chart_repository_url, '/')
+    ) dependents;
+end
+$$ language plpgsql;
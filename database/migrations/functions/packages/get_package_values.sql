@@ -0,0 +1,13 @@
+-- get_package_values returns the default values (values.yaml) of the package
+-- version identified by the input provided.
+create or replace function get_package_values(p_input jsonb)
+returns setof text as $$
+    select s.default_values
+    from package p
+    join snapshot s using (package_id)
+    join chart_repository r using (chart_repository_id)
+    where r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name'
+    and s.version = p_input->>'version'
+    and s.default_values is not null;
+$$ language sql;
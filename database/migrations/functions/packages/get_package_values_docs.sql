@@ -0,0 +1,14 @@
+-- get_package_values_docs returns the documentation of the parameters
+-- available in the default values (values.yaml) of the package version
+-- identified by the input provided.
+create or replace function get_package_values_docs(p_input jsonb)
+returns setof json as $$
+    select s.values_docs::json
+    from package p
+    join snapshot s using (package_id)
+    join chart_repository r using (chart_repository_id)
+    where r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name'
+    and s.version = p_input->>'version'
+    and s.values_docs is not null;
+$$ language sql;
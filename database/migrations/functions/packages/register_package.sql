@@ -2,12 +2,22 @@
 -- involves registering or updating the package entity when needed, registering
 -- a snapshot for the package version and creating/updating/deleting the
 -- package maintainers as needed depending on the ones present in the latest
--- package version.
+-- package version. Versions yanked by the repository owner are never used as
+-- the package's latest version (unless all of them have been yanked), and
+-- registering them again does not unyank them. Versions of packages from
+-- hosted chart repositories cannot be overwritten: registering an existing
+-- one raises a unique_violation error.
 create or replace function register_package(p_pkg jsonb)
 returns void as $$
 declare
     v_package_id uuid;
     v_chart_repository_id text := (p_pkg->'chart_repository')->>'chart_repository_id';
+    v_hosted boolean := exists (
+        select chart_repository_id
+        from chart_repository
+        where chart_repository_id = nullif(v_chart_repository_id, '')::uuid
+        and hosted = true
+    );
     v_package_latest_version_needs_update boolean := false;
     v_maintainer jsonb;
     v_maintainer_id uuid;
@@ -50,7 +60,21 @@ begin
         deprecated = excluded.deprecated,
         latest_version = excluded.latest_version,
         updated_at = current_timestamp
-    where semver_gte(p_pkg->>'version', package.latest_version) = true
+    where (
+        semver_gte(p_pkg->>'version', package.latest_version) = true
+        or exists (
+            select 1 from snapshot
+            where package_id = package.package_id
+            and version = package.latest_version
+            and yanked = true
+        )
+    )
+    and not exists (
+        select 1 from snapshot
+        where package_id = package.package_id
+        and version = p_pkg->>'version'
+        and yanked = true
+    )
     returning package_id into v_package_id;
 
     if found then
@@ -103,23 +127,85 @@ begin
         version,
         app_version,
         digest,
+        content_url,
+        archive_digest,
+        chart_metadata,
+        provenance_digest,
         readme,
         links,
-        data
+        data,
+        default_values,
+        values_schema,
+        values_docs,
+        dependencies,
+        container_images,
+        kube_version,
+        kube_version_ranges,
+        deprecated_apis,
+        lint_report,
+        quality_score,
+        signed,
+        signature_verified,
+        signer
     ) values (
         v_package_id,
         p_pkg->>'version',
         nullif(p_pkg->>'app_version', ''),
         nullif(p_pkg->>'digest', ''),
+        nullif(p_pkg->>'content_url', ''),
+        nullif(p_pkg->>'archive_digest', ''),
+        nullif(p_pkg->'chart_metadata', 'null'::jsonb),
+        nullif(p_pkg->>'provenance_digest', ''),
         nullif(p_pkg->>'readme', ''),
         p_pkg->'links',
-        p_pkg->'data'
+        p_pkg->'data',
+        nullif(p_pkg->>'default_values', ''),
+        nullif(p_pkg->'values_schema', 'null'::jsonb),
+        nullif(p_pkg->'values_docs', 'null'::jsonb),
+        nullif(p_pkg->'dependencies', 'null'::jsonb),
+        (select nullif(array(select jsonb_array_elements_text(nullif(p_pkg->'container_images', 'null'::jsonb))), '{}')::text[]),
+        nullif(p_pkg->>'kube_version', ''),
+        case when jsonb_typeof(p_pkg->'kube_version_ranges') = 'array' then
+            array(
+                select int4range((r->>'min')::int, (r->>'max')::int, '[]')
+                from jsonb_array_elements(p_pkg->'kube_version_ranges') r
+            )
+        else null end,
+        nullif(p_pkg->'deprecated_apis', 'null'::jsonb),
+        nullif(p_pkg->'lint_report', 'null'::jsonb),
+        (p_pkg->>'quality_score')::smallint,
+        coalesce((p_pkg->>'signed')::boolean, false),
+        coalesce((p_pkg->>'signature_verified')::boolean, false),
+        nullif(p_pkg->>'signer', '')
     )
     on conflict (package_id, version) do update
     set
         app_version = excluded.app_version,
         digest = excluded.digest,
+        content_url = excluded.content_url,
+        archive_digest = excluded.archive_digest,
+        chart_metadata = excluded.chart_metadata,
+        provenance_digest = excluded.provenance_digest,
         readme = excluded.readme,
-        links = excluded.links;
+        links = excluded.links,
+        data = excluded.data,
+        default_values = excluded.default_values,
+        values_schema = excluded.values_schema,
+        values_docs = excluded.values_docs,
+        dependencies = excluded.dependencies,
+        container_images = excluded.container_images,
+        kube_version = excluded.kube_version,
+        kube_version_ranges = excluded.kube_version_ranges,
+        deprecated_apis = excluded.deprecated_apis,
+        lint_report = excluded.lint_report,
+        quality_score = excluded.quality_score,
+        signed = excluded.signed,
+        signature_verified = excluded.signature_verified,
+        signer = excluded.signer
+    where v_hosted = false;
+    if not found then
+        raise 'package version already exists'
+        using errcode = 'unique_violation';
+    end if;
 end
 $$ language plpgsql;
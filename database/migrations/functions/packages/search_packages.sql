@@ -5,6 +5,7 @@ returns setof json as $$
 declare
     v_package_kinds int[];
     v_chart_repositories text[];
+    v_images text[];
     v_facets boolean := (p_input->>'facets')::boolean;
 begin
     -- Prepare filters for later use
@@ -12,6 +13,8 @@ begin
     from jsonb_array_elements_text(p_input->'package_kinds') e;
     select array_agg(e::text) into v_chart_repositories
     from jsonb_array_elements_text(p_input->'chart_repositories') e;
+    select array_agg(lower(e::text)) into v_images
+    from jsonb_array_elements_text(p_input->'images') e;
 
     return query
     with packages_applying_text_and_deprecated_filters as (
@@ -27,6 +30,9 @@ begin
             p.deprecated,
             s.version,
             s.app_version,
+            s.signed,
+            s.container_images,
+            s.kube_version_ranges,
             r.name as chart_repository_name,
             r.display_name as chart_repository_display_name
         from package p
@@ -38,6 +44,14 @@ begin
             case when p_input ? 'text' and p_input->>'text' <> '' then
                 websearch_to_tsquery(p_input->>'text') @@ p.tsdoc
             else true end
+        and
+            case when p_input ? 'kube_version' then
+                kube_version_ranges is null or exists (
+                    select 1
+                    from unnest(kube_version_ranges) r
+                    where r @> (p_input->>'kube_version')::int
+                )
+            else true end
         and
             case when p_input ? 'deprecated' and (p_input->>'deprecated')::boolean = true then
                 true
@@ -52,6 +66,18 @@ begin
         and
             case when cardinality(v_chart_repositories) > 0
             then chart_repository_name = any(v_chart_repositories) else true end
+        and
+            case when p_input ? 'signed' and (p_input->>'signed')::boolean = true then
+                signed = true
+            else true end
+        and
+            case when cardinality(v_images) > 0 then
+                exists (
+                    select 1
+                    from unnest(container_images) ci, unnest(v_images) i
+                    where strpos(lower(ci), i) > 0
+                )
+            else true end
         and
             case when p_input ? 'deprecated' and (p_input->>'deprecated')::boolean = true then
                 true
@@ -74,6 +100,7 @@ begin
                         'deprecated', deprecated,
                         'version', version,
                         'app_version', app_version,
+                        'signed', signed,
                         'chart_repository', (select nullif(
                             jsonb_build_object(
                                 'name', chart_repository_name,
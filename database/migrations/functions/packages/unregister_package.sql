@@ -0,0 +1,38 @@
+-- unregister_package unregisters the provided package version from the
+-- database. When the version unregistered was the package's latest version,
+-- the latest version is updated to the greatest one still available (yanked
+-- versions are ignored unless all of them have been yanked). If the package
+-- has no versions left, the package is deleted.
+create or replace function unregister_package(p_pkg jsonb)
+returns void as $$
+declare
+    v_package_id uuid;
+    v_latest_version text;
+begin
+    -- Get package id and latest version
+    select package_id, latest_version into v_package_id, v_latest_version
+    from package
+    where package_kind_id = (p_pkg->>'kind')::int
+    and chart_repository_id = ((p_pkg->'chart_repository')->>'chart_repository_id')::uuid
+    and name = p_pkg->>'name';
+    if not found then
+        return;
+    end if;
+
+    -- Delete package snapshot
+    delete from snapshot
+    where package_id = v_package_id
+    and version = p_pkg->>'version';
+
+    -- Delete package if it has no snapshots left
+    if not exists (select 1 from snapshot where package_id = v_package_id) then
+        delete from package where package_id = v_package_id;
+        return;
+    end if;
+
+    -- Update package latest version if needed
+    if v_latest_version = p_pkg->>'version' then
+        perform update_package_latest_version(v_package_id);
+    end if;
+end
+$$ language plpgsql;
@@ -0,0 +1,31 @@
+-- update_package_latest_version sets the latest version of the provided
+-- package to the greatest version available that has not been yanked. When all
+-- the package versions have been yanked, the greatest one is used.
+create or replace function update_package_latest_version(p_package_id uuid)
+returns void as $$
+declare
+    v_latest_version text;
+    v_version text;
+    v_all_yanked boolean;
+begin
+    select not exists (
+        select 1 from snapshot where package_id = p_package_id and yanked = false
+    ) into v_all_yanked;
+
+    for v_version in
+        select version from snapshot
+        where package_id = p_package_id
+        and (yanked = false or v_all_yanked)
+    loop
+        if v_latest_version is null or semver_gte(v_version, v_latest_version) then
+            v_latest_version := v_version;
+        end if;
+    end loop;
+
+    update package set
+        latest_version = v_latest_version,
+        updated_at = current_timestamp
+    where package_id = p_package_id
+    and latest_version is distinct from v_latest_version;
+end
+$$ language plpgsql;
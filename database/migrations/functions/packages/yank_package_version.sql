@@ -0,0 +1,30 @@
+-- yank_package_version marks the provided package version as yanked, so that
+-- it is not considered when computing the package's latest version. Only the
+-- user owning the chart repository (or the members of the organization which
+-- owns it) can yank its packages versions.
+create or replace function yank_package_version(p_user_id uuid, p_input jsonb)
+returns void as $$
+declare
+    v_package_id uuid;
+begin
+    if not user_has_access_to_chart_repository(p_user_id, p_input->>'chart_repository_name') then
+        raise insufficient_privilege;
+    end if;
+
+    update snapshot s set
+        yanked = true,
+        yanked_reason = nullif(p_input->>'reason', '')
+    from package p
+    join chart_repository r using (chart_repository_id)
+    where s.package_id = p.package_id
+    and r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name'
+    and s.version = p_input->>'version'
+    returning p.package_id into v_package_id;
+    if not found then
+        raise 'package version not found';
+    end if;
+
+    perform update_package_latest_version(v_package_id);
+end
+$$ language plpgsql;
@@ -0,0 +1,7 @@
+alter table snapshot add column default_values text check (default_values <> '');
+alter table snapshot add column values_schema jsonb;
+
+---- create above / drop below ----
+
+alter table snapshot drop column values_schema;
+alter table snapshot drop column default_values;
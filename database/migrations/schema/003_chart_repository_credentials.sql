@@ -0,0 +1,5 @@
+alter table chart_repository add column credentials text check (credentials <> '');
+
+---- create above / drop below ----
+
+alter table chart_repository drop column credentials;
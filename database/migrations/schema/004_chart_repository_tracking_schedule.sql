@@ -0,0 +1,11 @@
+alter table chart_repository add column tracking_interval integer not null default 30 check (tracking_interval > 0);
+alter table chart_repository add column tracking_jitter integer not null default 5 check (tracking_jitter >= 0);
+alter table chart_repository add column next_tracking_ts timestamptz;
+create index chart_repository_next_tracking_ts_idx on chart_repository (next_tracking_ts);
+
+---- create above / drop below ----
+
+drop index chart_repository_next_tracking_ts_idx;
+alter table chart_repository drop column next_tracking_ts;
+alter table chart_repository drop column tracking_jitter;
+alter table chart_repository drop column tracking_interval;
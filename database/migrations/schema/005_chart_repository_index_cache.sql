@@ -0,0 +1,5 @@
+alter table chart_repository add column index_cache jsonb;
+
+---- create above / drop below ----
+
+alter table chart_repository drop column index_cache;
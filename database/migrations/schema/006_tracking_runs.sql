@@ -0,0 +1,28 @@
+create table if not exists tracking_run (
+    tracking_run_id uuid primary key default gen_random_uuid(),
+    chart_repository_id uuid not null references chart_repository on delete cascade,
+    started_at timestamptz not null,
+    finished_at timestamptz not null,
+    processed integer not null default 0,
+    registered integer not null default 0,
+    failed integer not null default 0
+);
+
+create index tracking_run_chart_repository_id_idx on tracking_run (chart_repository_id);
+
+create table if not exists tracking_error (
+    tracking_error_id uuid primary key default gen_random_uuid(),
+    tracking_run_id uuid not null references tracking_run on delete cascade,
+    kind text not null check (kind <> ''),
+    chart_name text check (chart_name <> ''),
+    chart_version text check (chart_version <> ''),
+    url text check (url <> ''),
+    message text not null check (message <> '')
+);
+
+create index tracking_error_tracking_run_id_idx on tracking_error (tracking_run_id);
+
+---- create above / drop below ----
+
+drop table if exists tracking_error;
+drop table if exists tracking_run;
@@ -0,0 +1,14 @@
+create table if not exists tracking_request (
+    tracking_request_id uuid primary key default gen_random_uuid(),
+    chart_repository_id uuid not null references chart_repository on delete cascade,
+    status text not null default 'pending' check (status in ('pending', 'completed')),
+    created_at timestamptz default current_timestamp not null,
+    completed_at timestamptz,
+    tracking_run_id uuid references tracking_run on delete set null
+);
+
+create index tracking_request_chart_repository_id_idx on tracking_request (chart_repository_id);
+
+---- create above / drop below ----
+
+drop table if exists tracking_request;
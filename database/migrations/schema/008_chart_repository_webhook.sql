@@ -0,0 +1,5 @@
+alter table chart_repository add column webhook_secret text check (webhook_secret <> '');
+
+---- create above / drop below ----
+
+alter table chart_repository drop column webhook_secret;
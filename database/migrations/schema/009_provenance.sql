@@ -0,0 +1,13 @@
+alter table chart_repository add column keyring text check (keyring <> '');
+
+alter table snapshot add column signed boolean not null default false;
+alter table snapshot add column signature_verified boolean not null default false;
+alter table snapshot add column signer text check (signer <> '');
+
+---- create above / drop below ----
+
+alter table snapshot drop column signer;
+alter table snapshot drop column signature_verified;
+alter table snapshot drop column signed;
+
+alter table chart_repository drop column keyring;
@@ -0,0 +1,7 @@
+alter table snapshot add column dependencies jsonb;
+create index snapshot_dependencies_idx on snapshot using gin (dependencies jsonb_path_ops);
+
+---- create above / drop below ----
+
+drop index snapshot_dependencies_idx;
+alter table snapshot drop column dependencies;
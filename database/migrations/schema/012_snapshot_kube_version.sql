@@ -0,0 +1,7 @@
+alter table snapshot add column kube_version text check (kube_version <> '');
+alter table snapshot add column kube_version_ranges int4range[];
+
+---- create above / drop below ----
+
+alter table snapshot drop column kube_version_ranges;
+alter table snapshot drop column kube_version;
@@ -0,0 +1,5 @@
+alter table snapshot add column deprecated_apis jsonb;
+
+---- create above / drop below ----
+
+alter table snapshot drop column deprecated_apis;
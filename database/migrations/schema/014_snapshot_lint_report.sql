@@ -0,0 +1,7 @@
+alter table snapshot add column lint_report jsonb;
+alter table snapshot add column quality_score smallint check (quality_score between 0 and 100);
+
+---- create above / drop below ----
+
+alter table snapshot drop column quality_score;
+alter table snapshot drop column lint_report;
@@ -0,0 +1,5 @@
+alter table snapshot add column content_url text check (content_url <> '');
+
+---- create above / drop below ----
+
+alter table snapshot drop column content_url;
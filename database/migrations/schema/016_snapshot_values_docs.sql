@@ -0,0 +1,5 @@
+alter table snapshot add column values_docs jsonb;
+
+---- create above / drop below ----
+
+alter table snapshot drop column values_docs;
@@ -0,0 +1,14 @@
+create table if not exists chart_archive (
+    digest text primary key check (digest <> ''),
+    data bytea not null,
+    created_at timestamptz default current_timestamp not null
+);
+
+alter table snapshot add column archive_digest text check (archive_digest <> '');
+alter table snapshot add column chart_metadata jsonb;
+
+---- create above / drop below ----
+
+alter table snapshot drop column chart_metadata;
+alter table snapshot drop column archive_digest;
+drop table if exists chart_archive;
@@ -0,0 +1,7 @@
+alter table chart_repository add column hosted boolean not null default false;
+alter table snapshot add column provenance_digest text check (provenance_digest <> '');
+
+---- create above / drop below ----
+
+alter table snapshot drop column provenance_digest;
+alter table chart_repository drop column hosted;
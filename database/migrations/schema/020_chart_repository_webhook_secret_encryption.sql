@@ -0,0 +1,8 @@
+-- Webhook secrets are stored encrypted by the hub from now on, so the existing
+-- ones (stored in plaintext) are removed and must be generated again
+update chart_repository set webhook_secret = null;
+drop function if exists generate_chart_repository_webhook_secret(uuid, text);
+
+---- create above / drop below ----
+
+-- Nothing to do
@@ -0,0 +1,11 @@
+-- Hosted chart repositories are not tracked, so their tracking requests and
+-- webhook secrets are removed
+delete from tracking_request
+where chart_repository_id in (
+    select chart_repository_id from chart_repository where hosted = true
+);
+update chart_repository set webhook_secret = null where hosted = true;
+
+---- create above / drop below ----
+
+-- Nothing to do
@@ -0,0 +1,23 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Try getting a non existent chart archive
+select is_empty(
+    $$ select get_chart_archive('digest1') $$,
+    'Chart archive does not exist, nothing should be returned'
+);
+
+-- Seed chart archive
+insert into chart_archive (digest, data) values ('digest1', 'archive1Data'::bytea);
+
+-- Get chart archive just registered
+select results_eq(
+    $$ select get_chart_archive('digest1') $$,
+    $$ values ('archive1Data'::bytea) $$,
+    'Chart archive data should be returned'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
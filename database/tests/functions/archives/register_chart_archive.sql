@@ -0,0 +1,23 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Register chart archive
+select register_chart_archive('digest1', 'archive1Data'::bytea);
+select results_eq(
+    $$ select digest, data from chart_archive $$,
+    $$ values ('digest1', 'archive1Data'::bytea) $$,
+    'Chart archive should have been registered'
+);
+
+-- Register the same chart archive again
+select register_chart_archive('digest1', 'archive1Data'::bytea);
+select results_eq(
+    $$ select count(*) from chart_archive $$,
+    $$ values (1::bigint) $$,
+    'Chart archive should not have been registered twice'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -18,7 +18,11 @@ select add_chart_repository(:'user1ID', null, '
 {
     "name": "repo1",
     "display_name": "Repository 1",
-    "url": "repo1_url"
+    "url": "repo1_url",
+    "credentials": "encrypted-credentials",
+    "keyring": "keyring",
+    "tracking_interval": 60,
+    "tracking_jitter": 10
 }
 '::jsonb);
 select results_eq(
@@ -27,6 +31,10 @@ select results_eq(
             name,
             display_name,
             url,
+            credentials,
+            keyring,
+            tracking_interval,
+            tracking_jitter,
             user_id,
             organization_id
         from chart_repository
@@ -37,6 +45,10 @@ select results_eq(
             'repo1',
             'Repository 1',
             'repo1_url',
+            'encrypted-credentials',
+            'keyring',
+            60,
+            10,
             '00000000-0000-0000-0000-000000000001'::uuid,
             null::uuid
         )
@@ -49,7 +61,8 @@ select add_chart_repository(:'user1ID', 'org1', '
 {
     "name": "repo2",
     "display_name": "Repository 2",
-    "url": "repo2_url"
+    "url": "repo2_url",
+    "hosted": true
 }
 '::jsonb);
 select results_eq(
@@ -58,6 +71,9 @@ select results_eq(
             name,
             display_name,
             url,
+            tracking_interval,
+            tracking_jitter,
+            hosted,
             user_id,
             organization_id
         from chart_repository
@@ -68,6 +84,9 @@ select results_eq(
             'repo2',
             'Repository 2',
             'repo2_url',
+            30,
+            5,
+            true,
             null::uuid,
             '00000000-0000-0000-0000-000000000001'::uuid
         )
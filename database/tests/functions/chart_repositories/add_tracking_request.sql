@@ -0,0 +1,45 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id, hosted)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://hub.com/hosted/repo2', :'user1ID', true);
+
+-- Add tracking request
+select add_tracking_request(:'repo1ID');
+select results_eq(
+    $$ select chart_repository_id, status from tracking_request $$,
+    $$ values ('00000000-0000-0000-0000-000000000001'::uuid, 'pending') $$,
+    'Tracking request should exist and be pending'
+);
+select is(
+    add_tracking_request(:'repo1ID'),
+    (select tracking_request_id from tracking_request),
+    'Tracking request pending should be returned when adding a new one'
+);
+select is(
+    (select count(*) from tracking_request),
+    1::bigint,
+    'No new tracking requests should have been registered'
+);
+
+-- Add tracking request for hosted chart repository
+select throws_ok(
+    $$ select add_tracking_request('00000000-0000-0000-0000-000000000002') $$,
+    55000,
+    'hosted chart repositories cannot be tracked',
+    'Tracking requests should not be registered for hosted chart repositories'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
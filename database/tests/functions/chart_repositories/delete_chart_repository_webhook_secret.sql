@@ -0,0 +1,33 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id, webhook_secret)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID', 'secret');
+
+-- Delete webhook secret by user not owning the chart repository
+select throws_ok(
+    $$ select delete_chart_repository_webhook_secret('00000000-0000-0000-0000-000000000002', 'repo1') $$,
+    42501,
+    'insufficient_privilege',
+    'User not owning the chart repository should not be able to delete its webhook secret'
+);
+
+-- Delete webhook secret
+select delete_chart_repository_webhook_secret(:'user1ID', 'repo1');
+select is_empty(
+    $$ select webhook_secret from chart_repository where webhook_secret is not null $$,
+    'Webhook secret should have been deleted'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
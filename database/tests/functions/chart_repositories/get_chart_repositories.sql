@@ -10,8 +10,8 @@ select is(
 );
 
 -- Seed some chart repositories
-insert into chart_repository (chart_repository_id, name, display_name, url)
-values ('00000000-0000-0000-0000-000000000001', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, credentials)
+values ('00000000-0000-0000-0000-000000000001', 'repo1', 'Repo 1', 'https://repo1.com', 'encrypted-credentials');
 insert into chart_repository (chart_repository_id, name, display_name, url)
 values ('00000000-0000-0000-0000-000000000002', 'repo2', 'Repo 2', 'https://repo2.com');
 insert into chart_repository (chart_repository_id, name, display_name, url)
@@ -24,17 +24,35 @@ select is(
         "chart_repository_id": "00000000-0000-0000-0000-000000000001",
         "name": "repo1",
         "display_name": "Repo 1",
-        "url": "https://repo1.com"
+        "url": "https://repo1.com",
+        "credentials": "encrypted-credentials",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "index_cache": null
     }, {
         "chart_repository_id": "00000000-0000-0000-0000-000000000002",
         "name": "repo2",
         "display_name": "Repo 2",
-        "url": "https://repo2.com"
+        "url": "https://repo2.com",
+        "credentials": null,
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "index_cache": null
     }, {
         "chart_repository_id": "00000000-0000-0000-0000-000000000003",
         "name": "repo3",
         "display_name": "Repo 3",
-        "url": "https://repo3.com"
+        "url": "https://repo3.com",
+        "credentials": null,
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "index_cache": null
     }]'::jsonb,
     'Repositories are returned as a json array of objects'
 );
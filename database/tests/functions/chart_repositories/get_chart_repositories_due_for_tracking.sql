@@ -0,0 +1,72 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- No repositories at this point
+select is(
+    get_chart_repositories_due_for_tracking()::jsonb,
+    '[]'::jsonb,
+    'With no repositories an empty json array is returned'
+);
+
+-- Seed some chart repositories
+insert into chart_repository (chart_repository_id, name, display_name, url, credentials)
+values ('00000000-0000-0000-0000-000000000001', 'repo1', 'Repo 1', 'https://repo1.com', 'encrypted-credentials');
+insert into chart_repository (chart_repository_id, name, display_name, url, next_tracking_ts)
+values ('00000000-0000-0000-0000-000000000002', 'repo2', 'Repo 2', 'https://repo2.com', current_timestamp - '1 minute'::interval);
+insert into chart_repository (chart_repository_id, name, display_name, url, next_tracking_ts)
+values ('00000000-0000-0000-0000-000000000003', 'repo3', 'Repo 3', 'https://repo3.com', current_timestamp + '1 minute'::interval);
+insert into chart_repository (chart_repository_id, name, display_name, url, hosted)
+values ('00000000-0000-0000-0000-000000000004', 'repo4', 'Repo 4', 'https://hub.com/hosted/repo4', true);
+
+-- Repositories never tracked or whose next tracking is due are returned
+select is(
+    get_chart_repositories_due_for_tracking()::jsonb,
+    '[{
+        "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+        "name": "repo1",
+        "display_name": "Repo 1",
+        "url": "https://repo1.com",
+        "credentials": "encrypted-credentials",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "index_cache": null
+    }, {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000002",
+        "name": "repo2",
+        "display_name": "Repo 2",
+        "url": "https://repo2.com",
+        "credentials": null,
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "index_cache": null
+    }]'::jsonb,
+    'Repositories due for tracking are returned as a json array of objects'
+);
+
+-- Repositories with a tracking request pending are returned as well
+insert into tracking_request (chart_repository_id)
+values ('00000000-0000-0000-0000-000000000003');
+select results_eq(
+    $$ select r->>'name' from json_array_elements(get_chart_repositories_due_for_tracking()) as r $$,
+    $$ values ('repo1'), ('repo2'), ('repo3') $$,
+    'Repositories with a tracking request pending are due for tracking'
+);
+
+-- Hosted repositories are never due for tracking, even with a tracking
+-- request pending
+insert into tracking_request (chart_repository_id)
+values ('00000000-0000-0000-0000-000000000004');
+select results_eq(
+    $$ select r->>'name' from json_array_elements(get_chart_repositories_due_for_tracking()) as r $$,
+    $$ values ('repo1'), ('repo2'), ('repo3') $$,
+    'Hosted repositories are not due for tracking'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
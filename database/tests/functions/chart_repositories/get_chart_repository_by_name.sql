@@ -9,8 +9,8 @@ select is_empty(
 );
 
 -- Seed one chart repository
-insert into chart_repository (chart_repository_id, name, display_name, url)
-values ('00000000-0000-0000-0000-000000000001', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, credentials, index_cache)
+values ('00000000-0000-0000-0000-000000000001', 'repo1', 'Repo 1', 'https://repo1.com', 'encrypted-credentials', '{"etag": "etag1"}');
 
 -- One repository has just been seeded
 select is(
@@ -19,7 +19,13 @@ select is(
         "chart_repository_id": "00000000-0000-0000-0000-000000000001",
         "name": "repo1",
         "display_name": "Repo 1",
-        "url": "https://repo1.com"
+        "url": "https://repo1.com",
+        "credentials": "encrypted-credentials",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "index_cache": {"etag": "etag1"}
     }'::jsonb,
     'Repository just seeded is returned as a json object'
 );
@@ -0,0 +1,57 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set run1ID '00000000-0000-0000-0000-000000000001'
+\set request1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into tracking_run (tracking_run_id, chart_repository_id, started_at, finished_at)
+values (:'run1ID', :'repo1ID', '1970-01-01 00:00:00 UTC', '1970-01-01 00:01:00 UTC');
+insert into tracking_request (tracking_request_id, chart_repository_id, status, created_at, completed_at, tracking_run_id)
+values (:'request1ID', :'repo1ID', 'completed', '1970-01-01 00:00:00 UTC', '1970-01-01 00:01:00 UTC', :'run1ID');
+
+-- Run some tests
+select is(
+    get_chart_repository_tracking_request(:'user1ID', 'repo1', :'request1ID')::jsonb,
+    '{
+        "tracking_request_id": "00000000-0000-0000-0000-000000000001",
+        "status": "completed",
+        "created_at": 0,
+        "completed_at": 60,
+        "tracking_run_id": "00000000-0000-0000-0000-000000000001"
+    }'::jsonb,
+    'Tracking request should be returned as a json object'
+);
+select is_empty(
+    $$ select get_chart_repository_tracking_request(
+        '00000000-0000-0000-0000-000000000001',
+        'repo1',
+        '00000000-0000-0000-0000-000000000002'
+    ) $$,
+    'If tracking request requested does not exist no rows are returned'
+);
+select throws_ok(
+    $$
+        select get_chart_repository_tracking_request(
+            '00000000-0000-0000-0000-000000000002',
+            'repo1',
+            '00000000-0000-0000-0000-000000000001'
+        )
+    $$,
+    42501,
+    'insufficient_privilege',
+    'User not owning the chart repository should not be able to get its tracking requests'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,64 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set run1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', :'user1ID');
+insert into tracking_run (tracking_run_id, chart_repository_id, started_at, finished_at)
+values (:'run1ID', :'repo1ID', '1970-01-01 00:00:00 UTC', '1970-01-01 00:01:00 UTC');
+insert into tracking_error (tracking_run_id, kind, chart_name, chart_version, url, message)
+values (:'run1ID', 'chart_load', 'chart1', '1.0.0', 'https://repo1.com/chart1-1.0.0.tgz', 'error loading chart');
+insert into tracking_error (tracking_run_id, kind, message)
+values (:'run1ID', 'index', 'error loading index file');
+
+-- Run some tests
+select is(
+    get_chart_repository_tracking_run_errors(:'user1ID', 'repo1', :'run1ID')::jsonb,
+    '[{
+        "kind": "chart_load",
+        "chart_name": "chart1",
+        "chart_version": "1.0.0",
+        "url": "https://repo1.com/chart1-1.0.0.tgz",
+        "message": "error loading chart"
+    }, {
+        "kind": "index",
+        "chart_name": null,
+        "chart_version": null,
+        "url": null,
+        "message": "error loading index file"
+    }]'::jsonb,
+    'Tracking run errors are returned as a json array of objects'
+);
+select is(
+    get_chart_repository_tracking_run_errors(:'user1ID', 'repo2', :'run1ID')::jsonb,
+    '[]'::jsonb,
+    'Errors of tracking runs belonging to other repositories are not returned'
+);
+select throws_ok(
+    $$
+        select get_chart_repository_tracking_run_errors(
+            '00000000-0000-0000-0000-000000000002',
+            'repo1',
+            '00000000-0000-0000-0000-000000000001'
+        )
+    $$,
+    42501,
+    'insufficient_privilege',
+    'User not owning the chart repository should not be able to get its tracking runs errors'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
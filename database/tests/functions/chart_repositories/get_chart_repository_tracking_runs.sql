@@ -0,0 +1,64 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set run1ID '00000000-0000-0000-0000-000000000001'
+\set run2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+
+-- No tracking runs at this point
+select is(
+    get_chart_repository_tracking_runs(:'user1ID', 'repo1')::jsonb,
+    '[]'::jsonb,
+    'With no tracking runs an empty json array is returned'
+);
+
+-- Seed some tracking runs
+insert into tracking_run (tracking_run_id, chart_repository_id, started_at, finished_at, processed, registered, failed)
+values (:'run1ID', :'repo1ID', '1970-01-01 00:00:00 UTC', '1970-01-01 00:01:00 UTC', 2, 1, 1);
+insert into tracking_error (tracking_run_id, kind, message)
+values (:'run1ID', 'chart_load', 'error loading chart');
+insert into tracking_run (tracking_run_id, chart_repository_id, started_at, finished_at)
+values (:'run2ID', :'repo1ID', '1970-01-01 01:00:00 UTC', '1970-01-01 01:01:00 UTC');
+
+-- Run some tests
+select is(
+    get_chart_repository_tracking_runs(:'user1ID', 'repo1')::jsonb,
+    '[{
+        "tracking_run_id": "00000000-0000-0000-0000-000000000002",
+        "started_at": 3600,
+        "finished_at": 3660,
+        "processed": 0,
+        "registered": 0,
+        "failed": 0,
+        "errors_count": 0
+    }, {
+        "tracking_run_id": "00000000-0000-0000-0000-000000000001",
+        "started_at": 0,
+        "finished_at": 60,
+        "processed": 2,
+        "registered": 1,
+        "failed": 1,
+        "errors_count": 1
+    }]'::jsonb,
+    'Tracking runs are returned as a json array of objects (most recent first)'
+);
+select throws_ok(
+    $$ select get_chart_repository_tracking_runs('00000000-0000-0000-0000-000000000002', 'repo1') $$,
+    42501,
+    'insufficient_privilege',
+    'User not owning the chart repository should not be able to get its tracking runs'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
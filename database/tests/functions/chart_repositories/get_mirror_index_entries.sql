@@ -0,0 +1,66 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package1ID', 'Package1', '1.0.0', 0, :'repo1ID');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package2ID', 'package2', '1.0.0', 0, :'repo2ID');
+insert into snapshot (package_id, version, archive_digest, chart_metadata)
+values (:'package1ID', '1.0.0', 'digest-package1-1.0.0', '{"name": "Package1", "version": "1.0.0"}');
+insert into snapshot (package_id, version)
+values (:'package1ID', '0.0.9');
+insert into snapshot (package_id, version, archive_digest, chart_metadata)
+values (:'package2ID', '1.0.0', 'digest-package2-1.0.0', '{"name": "package2", "version": "1.0.0"}');
+
+-- Run some tests
+select is(
+    get_mirror_index_entries('repo1')::jsonb,
+    '[{
+        "chart_repository_name": "repo1",
+        "package_name": "package1",
+        "metadata": {"name": "Package1", "version": "1.0.0"},
+        "digest": "digest-package1-1.0.0"
+    }]'::jsonb,
+    'Mirrored charts versions of repo1 are returned'
+);
+select is(
+    get_mirror_index_entries(null)::jsonb,
+    '[{
+        "chart_repository_name": "repo1",
+        "package_name": "package1",
+        "metadata": {"name": "Package1", "version": "1.0.0"},
+        "digest": "digest-package1-1.0.0"
+    }, {
+        "chart_repository_name": "repo2",
+        "package_name": "package2",
+        "metadata": {"name": "package2", "version": "1.0.0"},
+        "digest": "digest-package2-1.0.0"
+    }]'::jsonb,
+    'Mirrored charts versions of all repositories are returned'
+);
+delete from snapshot where package_id = :'package2ID';
+select is(
+    get_mirror_index_entries('repo2')::jsonb,
+    '[]'::jsonb,
+    'An empty array is returned if the repository has no mirrored charts versions'
+);
+select is_empty(
+    $$ select get_mirror_index_entries('repo3') $$,
+    'No rows are returned if the repository does not exist'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
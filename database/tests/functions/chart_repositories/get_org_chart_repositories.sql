@@ -72,6 +72,11 @@ select is(
         "name": "repo1",
         "display_name": "Repo 1",
         "url": "https://repo1.com",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "next_tracking_ts": null,
         "last_tracking_ts": 0,
         "last_tracking_errors": "error1\\nerror2\\nerror3"
     }, {
@@ -79,6 +84,11 @@ select is(
         "name": "repo2",
         "display_name": "Repo 2",
         "url": "https://repo2.com",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "next_tracking_ts": null,
         "last_tracking_ts": null,
         "last_tracking_errors": null
     }]'::jsonb,
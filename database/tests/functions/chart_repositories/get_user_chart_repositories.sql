@@ -24,6 +24,7 @@ insert into chart_repository (
     url,
     last_tracking_ts,
     last_tracking_errors,
+    credentials,
     user_id
 ) values (
     '00000000-0000-0000-0000-000000000001',
@@ -32,6 +33,7 @@ insert into chart_repository (
     'https://repo1.com',
     '1970-01-01 00:00:00 UTC',
     'error1\nerror2\nerror3',
+    'encrypted-credentials',
     :'user1ID'
 );
 insert into chart_repository (
@@ -67,6 +69,11 @@ select is(
         "name": "repo1",
         "display_name": "Repo 1",
         "url": "https://repo1.com",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "next_tracking_ts": null,
         "last_tracking_ts": 0,
         "last_tracking_errors": "error1\\nerror2\\nerror3"
     }, {
@@ -74,10 +81,15 @@ select is(
         "name": "repo2",
         "display_name": "Repo 2",
         "url": "https://repo2.com",
+        "tracking_interval": 30,
+        "tracking_jitter": 5,
+        "keyring": null,
+        "hosted": false,
+        "next_tracking_ts": null,
         "last_tracking_ts": null,
         "last_tracking_errors": null
     }]'::jsonb,
-    'Repositories belonging to user provided are returned as a json array of objects'
+    'Repositories belonging to user provided are returned as a json array of objects (without credentials)'
 );
 select is(
     get_user_chart_repositories(null)::jsonb,
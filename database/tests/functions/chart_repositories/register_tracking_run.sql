@@ -0,0 +1,127 @@
+-- Start transaction and plan tests
+begin;
+select plan(7);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set request1ID '00000000-0000-0000-0000-000000000001'
+\set request2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into tracking_request (tracking_request_id, chart_repository_id, created_at)
+values (:'request1ID', :'repo1ID', to_timestamp(1589999999));
+insert into tracking_request (tracking_request_id, chart_repository_id, created_at)
+values (:'request2ID', :'repo1ID', to_timestamp(1590000001));
+
+-- Register tracking run
+select register_tracking_run('
+{
+    "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+    "started_at": 1590000000,
+    "finished_at": 1590000060,
+    "processed": 3,
+    "registered": 2,
+    "failed": 1,
+    "errors": [
+        {
+            "kind": "chart_load",
+            "chart_name": "chart1",
+            "chart_version": "1.0.0",
+            "url": "https://repo1.com/chart1-1.0.0.tgz",
+            "message": "error loading chart"
+        }
+    ]
+}
+');
+select results_eq(
+    $$
+        select
+            chart_repository_id,
+            floor(extract(epoch from started_at)),
+            floor(extract(epoch from finished_at)),
+            processed,
+            registered,
+            failed
+        from tracking_run
+    $$,
+    $$
+        values (
+            '00000000-0000-0000-0000-000000000001'::uuid,
+            1590000000::double precision,
+            1590000060::double precision,
+            3,
+            2,
+            1
+        )
+    $$,
+    'Tracking run should exist'
+);
+select results_eq(
+    $$ select kind, chart_name, chart_version, url, message from tracking_error $$,
+    $$
+        values (
+            'chart_load',
+            'chart1',
+            '1.0.0',
+            'https://repo1.com/chart1-1.0.0.tgz',
+            'error loading chart'
+        )
+    $$,
+    'Tracking error should exist'
+);
+select results_eq(
+    $$
+        select status, tracking_run_id = (select tracking_run_id from tracking_run)
+        from tracking_request
+        where tracking_request_id = '00000000-0000-0000-0000-000000000001'
+    $$,
+    $$ values ('completed', true) $$,
+    'Tracking request created before the tracking run started should have been completed'
+);
+select results_eq(
+    $$
+        select status, tracking_run_id
+        from tracking_request
+        where tracking_request_id = '00000000-0000-0000-0000-000000000002'
+    $$,
+    $$ values ('pending', null::uuid) $$,
+    'Tracking request created after the tracking run started should still be pending'
+);
+
+-- Register more tracking runs, only the most recent ones should be kept
+select register_tracking_run(format('
+{
+    "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+    "started_at": %s,
+    "finished_at": %s
+}
+', 1590000000 + n * 3600, 1590000060 + n * 3600)::jsonb)
+from generate_series(1, 25) as n;
+select is(
+    (select count(*) from tracking_run where chart_repository_id = :'repo1ID'),
+    25::bigint,
+    'Only the 25 most recent tracking runs should have been kept'
+);
+select is_empty(
+    $$
+        select *
+        from tracking_request
+        where tracking_request_id = '00000000-0000-0000-0000-000000000001'
+    $$,
+    'Tracking request completed by a deleted tracking run should have been deleted'
+);
+select results_eq(
+    $$
+        select status
+        from tracking_request
+        where tracking_request_id = '00000000-0000-0000-0000-000000000002'
+    $$,
+    $$ values ('completed') $$,
+    'Tracking request completed by a tracking run still available should have been kept'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
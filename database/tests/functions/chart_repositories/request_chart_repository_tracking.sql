@@ -0,0 +1,55 @@
+-- Start transaction and plan tests
+begin;
+select plan(5);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id, hosted)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://hub.com/hosted/repo2', :'user1ID', true);
+
+-- Request chart repository tracking
+select request_chart_repository_tracking(:'user1ID', 'repo1');
+select results_eq(
+    $$ select chart_repository_id, status from tracking_request $$,
+    $$ values ('00000000-0000-0000-0000-000000000001'::uuid, 'pending') $$,
+    'Tracking request should exist and be pending'
+);
+select is(
+    request_chart_repository_tracking(:'user1ID', 'repo1')->>'tracking_request_id',
+    (select tracking_request_id::text from tracking_request),
+    'Tracking request pending should be returned when requesting the tracking again'
+);
+select is(
+    (select count(*) from tracking_request),
+    1::bigint,
+    'No new tracking requests should have been registered'
+);
+
+-- Request chart repository tracking by user not owning it
+select throws_ok(
+    $$ select request_chart_repository_tracking('00000000-0000-0000-0000-000000000002', 'repo1') $$,
+    42501,
+    'insufficient_privilege',
+    'User not owning the chart repository should not be able to request its tracking'
+);
+
+-- Request hosted chart repository tracking
+select throws_ok(
+    $$ select request_chart_repository_tracking('00000000-0000-0000-0000-000000000001', 'repo2') $$,
+    55000,
+    'hosted chart repositories cannot be tracked',
+    'Hosted chart repositories tracking should not be requested'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
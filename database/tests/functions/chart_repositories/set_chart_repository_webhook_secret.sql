@@ -0,0 +1,55 @@
+-- Start transaction and plan tests
+begin;
+select plan(5);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id, hosted)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://hub.com/hosted/repo2', :'user1ID', true);
+
+-- Set webhook secret
+select is(
+    set_chart_repository_webhook_secret(:'user1ID', 'repo1', 'encryptedSecret1'),
+    :'repo1ID'::uuid,
+    'Chart repository id should be returned'
+);
+select set_chart_repository_webhook_secret(:'user1ID', 'repo1', 'encryptedSecret2');
+select is(
+    (select webhook_secret from chart_repository where name = 'repo1'),
+    'encryptedSecret2',
+    'Webhook secret should be replaced when setting a new one'
+);
+
+-- Set webhook secret by user not owning the chart repository
+select throws_ok(
+    $$ select set_chart_repository_webhook_secret('00000000-0000-0000-0000-000000000002', 'repo1', 'secret') $$,
+    42501,
+    'insufficient_privilege',
+    'User not owning the chart repository should not be able to set a webhook secret'
+);
+
+-- Set webhook secret of hosted chart repository
+select throws_ok(
+    $$ select set_chart_repository_webhook_secret('00000000-0000-0000-0000-000000000001', 'repo2', 'secret') $$,
+    55000,
+    'hosted chart repositories cannot be tracked',
+    'Webhooks should not be enabled for hosted chart repositories'
+);
+select is(
+    (select webhook_secret from chart_repository where name = 'repo2'),
+    null,
+    'Hosted chart repository webhook secret should not have been set'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(4);
+select plan(12);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -8,6 +8,7 @@ select plan(4);
 \set org1ID '00000000-0000-0000-0000-000000000001'
 \set repo1ID '00000000-0000-0000-0000-000000000001'
 \set repo2ID '00000000-0000-0000-0000-000000000002'
+\set repo3ID '00000000-0000-0000-0000-000000000003'
 
 -- Seed some data
 insert into "user" (user_id, alias, email)
@@ -15,10 +16,12 @@ values (:'user1ID', 'user1', 'user1@email.com');
 insert into organization (organization_id, name, display_name, description, home_url)
 values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
 insert into user__organization (user_id, organization_id, confirmed) values(:'user1ID', :'org1ID', true);
-insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
-values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, credentials, index_cache, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', 'encrypted-credentials', '{"etag": "etag1"}', :'user1ID');
 insert into chart_repository (chart_repository_id, name, display_name, url, organization_id)
 values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', :'org1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, hosted, user_id)
+values (:'repo3ID', 'repo3', 'Repo 3', 'https://hub.com/api/v1/hosted/repo3', true, :'user1ID');
 
 -- Try to update repository owned by a user by other user
 select throws_ok(
@@ -71,6 +74,80 @@ select results_eq(
     $$,
     'Chart repository should have been updated by user who owns it'
 );
+select results_eq(
+    $$ select credentials from chart_repository where name = 'repo1' $$,
+    $$ values ('encrypted-credentials') $$,
+    'Chart repository credentials should not have been updated as they were not provided'
+);
+select results_eq(
+    $$ select index_cache from chart_repository where name = 'repo1' $$,
+    $$ values (null::jsonb) $$,
+    'Chart repository index cache should have been reset as the url changed'
+);
+
+-- Update chart repository credentials (an empty value removes them)
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo1",
+    "display_name": "Repo 1 updated",
+    "url": "https://repo1.com/updated",
+    "credentials": ""
+}
+'::jsonb);
+select results_eq(
+    $$ select credentials from chart_repository where name = 'repo1' $$,
+    $$ values (null::text) $$,
+    'Chart repository credentials should have been removed'
+);
+
+-- Update chart repository keyring (an empty value removes it)
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo1",
+    "display_name": "Repo 1 updated",
+    "url": "https://repo1.com/updated",
+    "keyring": "keyring"
+}
+'::jsonb);
+select results_eq(
+    $$ select keyring from chart_repository where name = 'repo1' $$,
+    $$ values ('keyring') $$,
+    'Chart repository keyring should have been updated'
+);
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo1",
+    "display_name": "Repo 1 updated",
+    "url": "https://repo1.com/updated",
+    "keyring": ""
+}
+'::jsonb);
+select results_eq(
+    $$ select keyring from chart_repository where name = 'repo1' $$,
+    $$ values (null::text) $$,
+    'Chart repository keyring should have been removed'
+);
+
+-- Update chart repository tracking schedule settings
+select results_eq(
+    $$ select tracking_interval, tracking_jitter from chart_repository where name = 'repo1' $$,
+    $$ values (30, 5) $$,
+    'Chart repository tracking schedule should not have been updated as it was not provided'
+);
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo1",
+    "display_name": "Repo 1 updated",
+    "url": "https://repo1.com/updated",
+    "tracking_interval": 120,
+    "tracking_jitter": 15
+}
+'::jsonb);
+select results_eq(
+    $$ select tracking_interval, tracking_jitter from chart_repository where name = 'repo1' $$,
+    $$ values (120, 15) $$,
+    'Chart repository tracking schedule should have been updated'
+);
 
 -- Update chart repository owned by organization (requesting user belongs to organization)
 select update_chart_repository(:'user1ID', '
@@ -92,6 +169,26 @@ select results_eq(
     'Chart repository should have been updated by user who belongs to owning organization'
 );
 
+-- Update hosted chart repository (url cannot be updated)
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo3",
+    "display_name": "Repo 3 updated",
+    "url": "https://repo3.com"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select name, display_name, url
+        from chart_repository
+        where name = 'repo3'
+    $$,
+    $$
+        values ('repo3', 'Repo 3 updated', 'https://hub.com/api/v1/hosted/repo3')
+    $$,
+    'Hosted chart repository should have been updated, except its url'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
@@ -0,0 +1,45 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set org1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into organization (organization_id, name, display_name, description, home_url)
+values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
+insert into user__organization (user_id, organization_id, confirmed) values(:'user1ID', :'org1ID', true);
+insert into chart_repository (name, display_name, url, user_id)
+values ('repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (name, display_name, url, organization_id)
+values ('repo2', 'Repo 2', 'https://repo2.com', :'org1ID');
+
+-- Run some tests
+select is(
+    user_has_access_to_chart_repository(:'user1ID', 'repo1'),
+    true,
+    'User1 has access to repo1 as it owns it'
+);
+select is(
+    user_has_access_to_chart_repository(:'user1ID', 'repo2'),
+    true,
+    'User1 has access to repo2 as it belongs to the organization which owns it'
+);
+select is(
+    user_has_access_to_chart_repository(:'user2ID', 'repo1'),
+    false,
+    'User2 does not have access to repo1'
+);
+select is(
+    user_has_access_to_chart_repository(:'user2ID', 'repo2'),
+    false,
+    'User2 does not have access to repo2'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -1,11 +1,15 @@
 -- Start transaction and plan tests
 begin;
-select plan(6);
+select plan(7);
 
 -- Declare some variables
 \set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set repo3ID '00000000-0000-0000-0000-000000000003'
 \set package1ID '00000000-0000-0000-0000-000000000001'
 \set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+\set package4ID '00000000-0000-0000-0000-000000000004'
 \set maintainer1ID '00000000-0000-0000-0000-000000000001'
 \set maintainer2ID '00000000-0000-0000-0000-000000000002'
 \set image1ID '00000000-0000-0000-0000-000000000001'
@@ -44,6 +48,8 @@ select is_empty(
 -- Seed some packages
 insert into chart_repository (chart_repository_id, name, display_name, url)
 values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com');
 insert into maintainer (maintainer_id, name, email)
 values (:'maintainer1ID', 'name1', 'email1');
 insert into maintainer (maintainer_id, name, email)
@@ -84,7 +90,16 @@ insert into snapshot (
     digest,
     readme,
     links,
-    data
+    data,
+    dependencies,
+    container_images,
+    kube_version,
+    deprecated_apis,
+    lint_report,
+    quality_score,
+    signed,
+    signature_verified,
+    signer
 ) values (
     :'package1ID',
     '1.0.0',
@@ -92,7 +107,36 @@ insert into snapshot (
     'digest-package1-1.0.0',
     'readme-version-1.0.0',
     '{"link1": "https://link1", "link2": "https://link2"}',
-    '{"key": "value"}'
+    '{"key": "value"}',
+    '[{
+        "name": "postgresql",
+        "version": "^8.0.0",
+        "repository": "https://repo2.com/",
+        "condition": "postgresql.enabled"
+    }, {
+        "name": "redis",
+        "version": "10.0.0",
+        "repository": "https://charts.example.com",
+        "alias": "cache"
+    }]',
+    '{"repo/app:1.0.0"}',
+    '>=1.16.0-0',
+    '[{
+        "api_version": "networking.k8s.io/v1beta1",
+        "kind": "Ingress",
+        "deprecated_in": "1.19",
+        "removed_in": "1.22",
+        "replacement": "networking.k8s.io/v1"
+    }]',
+    '[{
+        "severity": "info",
+        "path": "Chart.yaml",
+        "message": "icon is recommended"
+    }]',
+    98,
+    true,
+    true,
+    'signer1 <signer1@email.com>'
 );
 insert into snapshot (
     package_id,
@@ -141,6 +185,19 @@ insert into snapshot (
     'readme-version-1.0.0',
     '{"key": "value"}'
 );
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package3ID',
+    'postgresql',
+    '8.1.0',
+    0,
+    :'repo2ID'
+);
 
 -- Packages have just been seeded
 select is(
@@ -168,9 +225,62 @@ select is(
             "key": "value"
         },
         "version": "1.0.0",
-        "available_versions": ["0.0.9", "1.0.0"],
+        "available_versions": [
+            {"version": "0.0.9", "yanked": false},
+            {"version": "1.0.0", "yanked": false}
+        ],
+        "yanked": false,
+        "yanked_reason": null,
         "app_version": "12.1.0",
         "digest": "digest-package1-1.0.0",
+        "signed": true,
+        "signature_verified": true,
+        "signer": "signer1 <signer1@email.com>",
+        "dependencies": [
+            {
+                "name": "postgresql",
+                "version": "^8.0.0",
+                "repository": "https://repo2.com/",
+                "condition": "postgresql.enabled",
+                "alias": null,
+                "package": {
+                    "package_id": "00000000-0000-0000-0000-000000000003",
+                    "name": "postgresql",
+                    "normalized_name": "postgresql",
+                    "chart_repository": {
+                        "name": "repo2",
+                        "display_name": "Repo 2"
+                    }
+                }
+            },
+            {
+                "name": "redis",
+                "version": "10.0.0",
+                "repository": "https://charts.example.com",
+                "condition": null,
+                "alias": "cache",
+                "package": null
+            }
+        ],
+        "container_images": ["repo/app:1.0.0"],
+        "kube_version": ">=1.16.0-0",
+        "deprecated_apis": [
+            {
+                "api_version": "networking.k8s.io/v1beta1",
+                "kind": "Ingress",
+                "deprecated_in": "1.19",
+                "removed_in": "1.22",
+                "replacement": "networking.k8s.io/v1"
+            }
+        ],
+        "lint_report": [
+            {
+                "severity": "info",
+                "path": "Chart.yaml",
+                "message": "icon is recommended"
+            }
+        ],
+        "quality_score": 98,
         "maintainers": [
             {
                 "name": "name1",
@@ -216,9 +326,23 @@ select is(
             "key": "value"
         },
         "version": "0.0.9",
-        "available_versions": ["0.0.9", "1.0.0"],
+        "available_versions": [
+            {"version": "0.0.9", "yanked": false},
+            {"version": "1.0.0", "yanked": false}
+        ],
+        "yanked": false,
+        "yanked_reason": null,
         "app_version": "12.0.0",
         "digest": "digest-package1-0.0.9",
+        "signed": false,
+        "signature_verified": false,
+        "signer": null,
+        "dependencies": null,
+        "container_images": null,
+        "kube_version": null,
+        "deprecated_apis": null,
+        "lint_report": null,
+        "quality_score": null,
         "maintainers": [
             {
                 "name": "name1",
@@ -256,18 +380,65 @@ select is(
         "readme": "readme-version-1.0.0",
         "links": null,
         "digest": null,
+        "signed": false,
+        "signature_verified": false,
+        "signer": null,
+        "dependencies": null,
+        "container_images": null,
+        "kube_version": null,
+        "deprecated_apis": null,
+        "lint_report": null,
+        "quality_score": null,
         "data": {
             "key": "value"
         },
         "version": "1.0.0",
         "app_version": null,
-        "available_versions": ["1.0.0"],
+        "available_versions": [
+            {"version": "1.0.0", "yanked": false}
+        ],
+        "yanked": false,
+        "yanked_reason": null,
         "maintainers": null,
         "chart_repository": null
     }'::jsonb,
     'Last package2 version is returned as a json object'
 );
 
+-- Add a chart repository sharing its url with repo2, which also provides a
+-- package matching one of the package1 dependencies
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo3ID', 'repo3', 'Repo 3', 'https://repo2.com/');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package4ID',
+    'postgresql',
+    '8.2.0',
+    0,
+    :'repo3ID'
+);
+select is(
+    (get_package('{
+        "package_name": "package-1",
+        "chart_repository_name": "repo1"
+    }')::jsonb)->'dependencies'->0->'package',
+    '{
+        "package_id": "00000000-0000-0000-0000-000000000003",
+        "name": "postgresql",
+        "normalized_name": "postgresql",
+        "chart_repository": {
+            "name": "repo2",
+            "display_name": "Repo 2"
+        }
+    }'::jsonb,
+    'Dependency package is resolved deterministically when several repositories share the same url'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
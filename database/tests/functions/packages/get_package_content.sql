@@ -0,0 +1,69 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package1ID',
+    'package1',
+    '1.0.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version, digest, content_url, archive_digest, provenance_digest)
+values (:'package1ID', '1.0.0', 'digest-package1-1.0.0', 'https://repo1.com/package1-1.0.0.tgz', 'archive-digest-package1-1.0.0', 'provenance-digest-package1-1.0.0');
+insert into snapshot (package_id, version)
+values (:'package1ID', '0.0.9');
+
+-- Run some tests
+select is(
+    get_package_content('{
+        "chart_repository_name": "repo1",
+        "package_name": "package1",
+        "version": "1.0.0"
+    }')::jsonb,
+    '{
+        "chart_repository_name": "repo1",
+        "url": "https://repo1.com/package1-1.0.0.tgz",
+        "digest": "digest-package1-1.0.0",
+        "archive_digest": "archive-digest-package1-1.0.0",
+        "provenance_digest": "provenance-digest-package1-1.0.0"
+    }'::jsonb,
+    'Content information of package1 version 1.0.0 is returned'
+);
+select is_empty(
+    $$
+        select get_package_content('{
+            "chart_repository_name": "repo1",
+            "package_name": "package1",
+            "version": "0.0.9"
+        }')
+    $$,
+    'No rows are returned if the package version has no content url'
+);
+select is_empty(
+    $$
+        select get_package_content('{
+            "chart_repository_name": "repo1",
+            "package_name": "package2",
+            "version": "1.0.0"
+        }')
+    $$,
+    'No rows are returned if the package requested does not exist'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,92 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+\set package4ID '00000000-0000-0000-0000-000000000004'
+
+-- No packages at this point
+select is_empty(
+    $$
+        select get_package_dependents('{
+            "chart_repository_name": "repo1",
+            "package_name": "postgresql"
+        }')
+    $$,
+    'If package requested does not exist no rows are returned'
+);
+
+-- Seed some packages
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package1ID', 'postgresql', '8.1.0', 0, :'repo1ID');
+insert into snapshot (package_id, version)
+values (:'package1ID', '8.1.0');
+insert into package (package_id, name, display_name, latest_version, package_kind_id, chart_repository_id)
+values (:'package2ID', 'app1', 'App 1', '1.0.0', 0, :'repo2ID');
+insert into snapshot (package_id, version, dependencies)
+values (:'package2ID', '0.9.0', '[{"name": "postgresql", "version": "7.0.0", "repository": "https://repo1.com"}]');
+insert into snapshot (package_id, version, dependencies)
+values (:'package2ID', '1.0.0', '[{"name": "postgresql", "version": "^8.0.0", "repository": "https://repo1.com/"}]');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package3ID', 'app2', '1.0.0', 0, :'repo2ID');
+insert into snapshot (package_id, version, dependencies)
+values (:'package3ID', '1.0.0', '[{"name": "postgresql", "version": "8.1.0", "repository": "https://other.repo"}]');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package4ID', 'app3', '2.0.0', 0, :'repo2ID');
+insert into snapshot (package_id, version, dependencies)
+values (:'package4ID', '1.0.0', '[{"name": "postgresql", "version": "8.0.0", "repository": "https://repo1.com"}]');
+insert into snapshot (package_id, version)
+values (:'package4ID', '2.0.0');
+
+-- Run some tests
+select is(
+    get_package_dependents('{
+        "chart_repository_name": "repo1",
+        "package_name": "postgresql"
+    }')::jsonb,
+    '[{
+        "package_id": "00000000-0000-0000-0000-000000000002",
+        "kind": 0,
+        "name": "app1",
+        "normalized_name": "app1",
+        "display_name": "App 1",
+        "logo_image_id": null,
+        "version": "1.0.0",
+        "dependency_version": "^8.0.0",
+        "chart_repository": {
+            "name": "repo2",
+            "display_name": "Repo 2"
+        }
+    }]'::jsonb,
+    'Only packages whose latest version depends on the chart in the same repository are returned'
+);
+select is(
+    get_package_dependents('{
+        "chart_repository_name": "repo2",
+        "package_name": "app1"
+    }')::jsonb,
+    '[]'::jsonb,
+    'Packages without dependents return an empty array'
+);
+select is_empty(
+    $$
+        select get_package_dependents('{
+            "chart_repository_name": "repo2",
+            "package_name": "postgresql"
+        }')
+    $$,
+    'If package requested does not exist in the repository provided no rows are returned'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
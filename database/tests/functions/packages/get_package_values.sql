@@ -0,0 +1,63 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package1ID',
+    'package1',
+    '1.0.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version, default_values)
+values (:'package1ID', '1.0.0', 'key: value');
+insert into snapshot (package_id, version)
+values (:'package1ID', '0.0.9');
+
+-- Run some tests
+select is(
+    get_package_values('{
+        "chart_repository_name": "repo1",
+        "package_name": "package1",
+        "version": "1.0.0"
+    }'),
+    'key: value',
+    'Default values of package1 version 1.0.0 are returned'
+);
+select is_empty(
+    $$
+        select get_package_values('{
+            "chart_repository_name": "repo1",
+            "package_name": "package1",
+            "version": "0.0.9"
+        }')
+    $$,
+    'No rows are returned if the package version has no default values'
+);
+select is_empty(
+    $$
+        select get_package_values('{
+            "chart_repository_name": "repo1",
+            "package_name": "package2",
+            "version": "1.0.0"
+        }')
+    $$,
+    'No rows are returned if the package requested does not exist'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
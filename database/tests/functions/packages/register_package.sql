@@ -1,13 +1,16 @@
 -- Start transaction and plan tests
 begin;
-select plan(10);
+select plan(14);
 
 -- Declare some variables
 \set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
 
--- Seed one chart repository
+-- Seed some chart repositories
 insert into chart_repository (chart_repository_id, name, display_name, url)
 values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, hosted)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://hub.com/hosted/repo2', true);
 
 -- Register package
 select register_package('
@@ -29,9 +32,63 @@ select register_package('
     "data": {
         "key": "value"
     },
+    "default_values": "key: value",
+    "values_schema": {
+        "type": "object"
+    },
+    "values_docs": [
+        {
+            "key": "replicaCount",
+            "type": "int",
+            "default": "1",
+            "description": "Number of replicas"
+        }
+    ],
     "version": "1.0.0",
     "app_version": "12.1.0",
     "digest": "digest-package1-1.0.0",
+    "content_url": "https://repo1.com/package1-1.0.0.tgz",
+    "archive_digest": "archive-digest-package1-1.0.0",
+    "chart_metadata": {
+        "name": "package1",
+        "version": "1.0.0"
+    },
+    "provenance_digest": "provenance-digest-package1-1.0.0",
+    "signed": true,
+    "signature_verified": true,
+    "signer": "signer1 <signer1@email.com>",
+    "dependencies": [
+        {
+            "name": "dependency1",
+            "version": "1.0.0",
+            "repository": "https://dependency1.repo"
+        }
+    ],
+    "container_images": ["repo/app:1.0.0"],
+    "kube_version": ">=1.16.0-0",
+    "kube_version_ranges": [
+        {
+            "min": 1016000,
+            "max": 1021999
+        }
+    ],
+    "deprecated_apis": [
+        {
+            "api_version": "networking.k8s.io/v1beta1",
+            "kind": "Ingress",
+            "deprecated_in": "1.19",
+            "removed_in": "1.22",
+            "replacement": "networking.k8s.io/v1"
+        }
+    ],
+    "lint_report": [
+        {
+            "severity": "info",
+            "path": "Chart.yaml",
+            "message": "icon is recommended"
+        }
+    ],
+    "quality_score": 98,
     "maintainers": [
         {
             "name": "name1",
@@ -89,9 +146,26 @@ select results_eq(
             s.version,
             s.app_version,
             s.digest,
+            s.content_url,
+            s.archive_digest,
+            s.chart_metadata,
+            s.provenance_digest,
             s.readme,
             s.links,
-            s.data
+            s.data,
+            s.default_values,
+            s.values_schema,
+            s.values_docs,
+            s.dependencies,
+            s.container_images,
+            s.kube_version,
+            s.kube_version_ranges,
+            s.deprecated_apis,
+            s.lint_report,
+            s.quality_score,
+            s.signed,
+            s.signature_verified,
+            s.signer
         from snapshot s
         join package p using (package_id)
         where name='package1'
@@ -102,9 +176,26 @@ select results_eq(
             '1.0.0',
             '12.1.0',
             'digest-package1-1.0.0',
+            'https://repo1.com/package1-1.0.0.tgz',
+            'archive-digest-package1-1.0.0',
+            '{"name": "package1", "version": "1.0.0"}'::jsonb,
+            'provenance-digest-package1-1.0.0',
             'readme-version-1.0.0',
             '{"link1": "https://link1", "link2": "https://link2"}'::jsonb,
-            '{"key": "value"}'::jsonb
+            '{"key": "value"}'::jsonb,
+            'key: value',
+            '{"type": "object"}'::jsonb,
+            '[{"key": "replicaCount", "type": "int", "default": "1", "description": "Number of replicas"}]'::jsonb,
+            '[{"name": "dependency1", "version": "1.0.0", "repository": "https://dependency1.repo"}]'::jsonb,
+            '{repo/app:1.0.0}'::text[],
+            '>=1.16.0-0',
+            '{"[1016000,1022000)"}'::int4range[],
+            '[{"api_version": "networking.k8s.io/v1beta1", "kind": "Ingress", "deprecated_in": "1.19", "removed_in": "1.22", "replacement": "networking.k8s.io/v1"}]'::jsonb,
+            '[{"severity": "info", "path": "Chart.yaml", "message": "icon is recommended"}]'::jsonb,
+            98::smallint,
+            true,
+            true,
+            'signer1 <signer1@email.com>'
         )
     $$,
     'Snapshot should exist'
@@ -288,6 +379,85 @@ select results_eq(
     'Package maintainers should not have been updated'
 );
 
+-- Yank the latest version of the package and register it again
+update snapshot set yanked = true, yanked_reason = 'broken release'
+where version = '2.0.0'
+and package_id = (select package_id from package where name = 'package1');
+select update_package_latest_version((select package_id from package where name = 'package1'));
+select register_package('
+{
+    "kind": 0,
+    "name": "package1",
+    "display_name": "Package 1 v2",
+    "description": "description v2",
+    "version": "2.0.0",
+    "app_version": "13.0.0",
+    "digest": "digest-package1-2.0.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+
+-- Check yanked version was not unyanked nor used as latest version
+select results_eq(
+    $$
+        select s.yanked, s.yanked_reason
+        from snapshot s
+        join package p using (package_id)
+        where name='package1'
+        and version='2.0.0'
+    $$,
+    $$ values (true, 'broken release') $$,
+    'Snapshot 2.0.0 should still be yanked'
+);
+select is(
+    (select latest_version from package where name = 'package1'),
+    '1.0.0',
+    'Package latest version should not have been set to the yanked version'
+);
+
+-- Register a package version in a hosted chart repository twice
+select register_package('
+{
+    "kind": 0,
+    "name": "package2",
+    "version": "1.0.0",
+    "digest": "digest-package2-1.0.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000002"
+    }
+}
+');
+select throws_ok(
+    $$
+        select register_package('
+        {
+            "kind": 0,
+            "name": "package2",
+            "version": "1.0.0",
+            "digest": "digest-package2-1.0.0-overwritten",
+            "chart_repository": {
+                "chart_repository_id": "00000000-0000-0000-0000-000000000002"
+            }
+        }
+        ')
+    $$,
+    23505,
+    'package version already exists',
+    'Hosted chart repositories package versions should not be overwritten'
+);
+select results_eq(
+    $$
+        select s.digest
+        from snapshot s
+        join package p using (package_id)
+        where p.name = 'package2'
+    $$,
+    $$ values ('digest-package2-1.0.0') $$,
+    'Hosted package version snapshot should not have been updated'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
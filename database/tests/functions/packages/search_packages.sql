@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(18);
+select plan(22);
 
 -- Declare some variables
 \set repo1ID '00000000-0000-0000-0000-000000000001'
@@ -65,14 +65,24 @@ insert into snapshot (
     app_version,
     digest,
     readme,
-    links
+    links,
+    kube_version,
+    kube_version_ranges,
+    signed,
+    signature_verified,
+    signer
 ) values (
     :'package1ID',
     '1.0.0',
     '12.1.0',
     'digest-package1-1.0.0',
     'readme',
-    '{"link1": "https://link1", "link2": "https://link2"}'
+    '{"link1": "https://link1", "link2": "https://link2"}',
+    '>=1.16.0-0',
+    '{"[1016000,)"}',
+    true,
+    true,
+    'signer1 <signer1@email.com>'
 );
 insert into snapshot (
     package_id,
@@ -120,14 +130,20 @@ insert into snapshot (
     app_version,
     digest,
     readme,
-    links
+    links,
+    container_images,
+    kube_version,
+    kube_version_ranges
 ) values (
     :'package2ID',
     '1.0.0',
     '12.1.0',
     'digest-package2-1.0.0',
     'readme',
-    '{"link1": "https://link1", "link2": "https://link2"}'
+    '{"link1": "https://link1", "link2": "https://link2"}',
+    '{"docker.io/bitnami/postgresql:11.7.0"}',
+    '<1.16.0',
+    '{"[,1015049]"}'
 );
 insert into snapshot (
     package_id,
@@ -167,12 +183,16 @@ insert into snapshot (
     package_id,
     version,
     readme,
-    links
+    links,
+    kube_version_ranges,
+    deprecated_apis
 ) values (
     :'package3ID',
     '1.0.0',
     'readme',
-    '{"link1": "https://link1", "link2": "https://link2"}'
+    '{"link1": "https://link1", "link2": "https://link2"}',
+    '{"[,1021999]"}',
+    '[{"api_version": "networking.k8s.io/v1beta1", "kind": "Ingress", "removed_in": "1.22"}]'
 );
 
 -- Some packages have just been seeded
@@ -191,6 +211,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -206,6 +227,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000002",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": false,
                 "description": "description",
                 "display_name": "Package 2",
                 "deprecated": true,
@@ -221,6 +243,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000003",
                 "version": "1.0.0",
                 "app_version": null,
+                "signed": false,
                 "description": "description",
                 "display_name": "Package 3",
                 "deprecated": null,
@@ -276,6 +299,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -291,6 +315,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000002",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": false,
                 "description": "description",
                 "display_name": "Package 2",
                 "deprecated": true,
@@ -344,6 +369,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -413,6 +439,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -448,6 +475,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -486,6 +514,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000002",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": false,
                 "description": "description",
                 "display_name": "Package 2",
                 "deprecated": true,
@@ -658,6 +687,165 @@ select is(
     'Facets: false Text: kw1 Kinds: 1, 2 | No packages or facets expected'
 );
 
+-- Tests with signed filter
+select is(
+    search_packages('{
+        "facets": false,
+        "signed": true,
+        "deprecated": true
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "signed": true,
+                "description": "description",
+                "display_name": "Package 1",
+                "deprecated": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Facets: false Signed: true | Package 1 expected - No facets expected'
+);
+
+-- Tests with images filter
+select is(
+    search_packages('{
+        "facets": false,
+        "images": ["Bitnami/PostgreSQL"],
+        "deprecated": true
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "kind": 0,
+                "name": "package2",
+                "normalized_name": "package2",
+                "logo_image_id": "00000000-0000-0000-0000-000000000002",
+                "package_id": "00000000-0000-0000-0000-000000000002",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "signed": false,
+                "description": "description",
+                "display_name": "Package 2",
+                "deprecated": true,
+                "chart_repository": {
+                    "name": "repo2",
+                    "display_name": "Repo 2"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Facets: false Images: Bitnami/PostgreSQL | Package 2 expected - No facets expected'
+);
+
+-- Tests with kubernetes version filter
+select is(
+    search_packages('{
+        "facets": false,
+        "kube_version": 1018003,
+        "deprecated": true
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "signed": true,
+                "description": "description",
+                "display_name": "Package 1",
+                "deprecated": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }, {
+                "kind": 1,
+                "name": "package3",
+                "normalized_name": "package3",
+                "logo_image_id": "00000000-0000-0000-0000-000000000003",
+                "package_id": "00000000-0000-0000-0000-000000000003",
+                "version": "1.0.0",
+                "app_version": null,
+                "signed": false,
+                "description": "description",
+                "display_name": "Package 3",
+                "deprecated": null,
+                "chart_repository": null
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 2
+        }
+    }'::jsonb,
+    'Facets: false KubeVersion: 1.18.3 | Packages 1 and 3 expected - No facets expected'
+);
+select is(
+    search_packages('{
+        "facets": false,
+        "kube_version": 1022000,
+        "deprecated": true
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "signed": true,
+                "description": "description",
+                "display_name": "Package 1",
+                "deprecated": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Facets: false KubeVersion: 1.22.0 | Package 1 expected (package 3 uses removed APIs) - No facets expected'
+);
+
 -- Tests with limit and offset
 select is(
     search_packages('{
@@ -676,6 +864,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -691,6 +880,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000002",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": false,
                 "description": "description",
                 "display_name": "Package 2",
                 "deprecated": true,
@@ -726,6 +916,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000001",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": true,
                 "description": "description",
                 "display_name": "Package 1",
                 "deprecated": null,
@@ -781,6 +972,7 @@ select is(
                 "package_id": "00000000-0000-0000-0000-000000000002",
                 "version": "1.0.0",
                 "app_version": "12.1.0",
+                "signed": false,
                 "description": "description",
                 "display_name": "Package 2",
                 "deprecated": true,
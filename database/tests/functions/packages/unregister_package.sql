@@ -0,0 +1,116 @@
+-- Start transaction and plan tests
+begin;
+select plan(5);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package1ID',
+    'package1',
+    '2.0.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version) values (:'package1ID', '2.0.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.10.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.9.0');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package2ID',
+    'package2',
+    '1.0.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version) values (:'package2ID', '1.0.0');
+
+-- Unregister an old package version
+select unregister_package('
+{
+    "kind": 0,
+    "name": "package1",
+    "version": "1.9.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+select results_eq(
+    $$ select version from snapshot where package_id = '00000000-0000-0000-0000-000000000001' order by version $$,
+    $$ values ('1.10.0'), ('2.0.0') $$,
+    'Snapshot 1.9.0 of package1 should have been deleted'
+);
+select is(
+    (select latest_version from package where package_id = '00000000-0000-0000-0000-000000000001'),
+    '2.0.0',
+    'package1 latest version should not have changed'
+);
+
+-- Unregister the latest package version
+select unregister_package('
+{
+    "kind": 0,
+    "name": "package1",
+    "version": "2.0.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+select is(
+    (select latest_version from package where package_id = '00000000-0000-0000-0000-000000000001'),
+    '1.10.0',
+    'package1 latest version should have been updated to 1.10.0'
+);
+
+-- Unregister the only package version available
+select unregister_package('
+{
+    "kind": 0,
+    "name": "package2",
+    "version": "1.0.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+select is_empty(
+    $$ select * from package where package_id = '00000000-0000-0000-0000-000000000002' $$,
+    'package2 should have been deleted as it has no versions left'
+);
+
+-- Unregister a package version that does not exist
+select lives_ok(
+    $$
+        select unregister_package('{
+            "kind": 0,
+            "name": "package3",
+            "version": "1.0.0",
+            "chart_repository": {
+                "chart_repository_id": "00000000-0000-0000-0000-000000000001"
+            }
+        }')
+    $$,
+    'Unregistering a package that does not exist should not fail'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
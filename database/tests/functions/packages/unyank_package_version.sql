@@ -0,0 +1,68 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package1ID',
+    'package1',
+    '1.0.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version) values (:'package1ID', '1.0.0');
+insert into snapshot (package_id, version, yanked, yanked_reason)
+values (:'package1ID', '2.0.0', true, 'broken release');
+
+-- Try to unyank a package version by a user not owning the chart repository
+select throws_ok(
+    $$
+        select unyank_package_version(
+            '00000000-0000-0000-0000-000000000002',
+            '{"chart_repository_name": "repo1", "package_name": "package1", "version": "2.0.0"}'
+        )
+    $$,
+    42501,
+    'insufficient_privilege',
+    'Package version unyank should fail because requesting user is not the owner'
+);
+
+-- Unyank package version
+select unyank_package_version(:'user1ID', '{
+    "chart_repository_name": "repo1",
+    "package_name": "package1",
+    "version": "2.0.0"
+}');
+select results_eq(
+    $$
+        select yanked, yanked_reason
+        from snapshot
+        where package_id = '00000000-0000-0000-0000-000000000001'
+        and version = '2.0.0'
+    $$,
+    $$ values (false, null::text) $$,
+    'Snapshot 2.0.0 should have been unyanked'
+);
+select is(
+    (select latest_version from package where package_id = :'package1ID'),
+    '2.0.0',
+    'package1 latest version should have been updated to 2.0.0'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,57 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package1ID',
+    'package1',
+    '1.9.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version) values (:'package1ID', '2.0.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.10.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.9.0');
+
+-- Update latest version
+select update_package_latest_version(:'package1ID');
+select is(
+    (select latest_version from package where package_id = :'package1ID'),
+    '2.0.0',
+    'package1 latest version should be 2.0.0'
+);
+
+-- Update latest version when the greatest version has been yanked
+update snapshot set yanked = true where package_id = :'package1ID' and version = '2.0.0';
+select update_package_latest_version(:'package1ID');
+select is(
+    (select latest_version from package where package_id = :'package1ID'),
+    '1.10.0',
+    'package1 latest version should be 1.10.0 as 2.0.0 has been yanked'
+);
+
+-- Update latest version when all versions have been yanked
+update snapshot set yanked = true where package_id = :'package1ID';
+select update_package_latest_version(:'package1ID');
+select is(
+    (select latest_version from package where package_id = :'package1ID'),
+    '2.0.0',
+    'package1 latest version should be 2.0.0 as all versions have been yanked'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,101 @@
+-- Start transaction and plan tests
+begin;
+select plan(5);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id
+) values (
+    :'package1ID',
+    'package1',
+    '2.0.0',
+    0,
+    :'repo1ID'
+);
+insert into snapshot (package_id, version) values (:'package1ID', '2.0.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.10.0');
+insert into snapshot (package_id, version) values (:'package1ID', '1.9.0');
+
+-- Try to yank a package version by a user not owning the chart repository
+select throws_ok(
+    $$
+        select yank_package_version(
+            '00000000-0000-0000-0000-000000000002',
+            '{"chart_repository_name": "repo1", "package_name": "package1", "version": "2.0.0"}'
+        )
+    $$,
+    42501,
+    'insufficient_privilege',
+    'Package version yank should fail because requesting user is not the owner'
+);
+
+-- Try to yank a package version that does not exist
+select throws_ok(
+    $$
+        select yank_package_version(
+            '00000000-0000-0000-0000-000000000001',
+            '{"chart_repository_name": "repo1", "package_name": "package1", "version": "3.0.0"}'
+        )
+    $$,
+    'P0001',
+    'package version not found',
+    'Package version yank should fail because the version does not exist'
+);
+
+-- Yank an old package version
+select yank_package_version(:'user1ID', '{
+    "chart_repository_name": "repo1",
+    "package_name": "package1",
+    "version": "1.9.0"
+}');
+select is(
+    (select latest_version from package where package_id = :'package1ID'),
+    '2.0.0',
+    'package1 latest version should not have changed'
+);
+
+-- Yank the latest package version
+select yank_package_version(:'user1ID', '{
+    "chart_repository_name": "repo1",
+    "package_name": "package1",
+    "version": "2.0.0",
+    "reason": "broken release"
+}');
+select results_eq(
+    $$
+        select version, yanked, yanked_reason
+        from snapshot
+        where package_id = '00000000-0000-0000-0000-000000000001'
+        order by version
+    $$,
+    $$
+        values
+            ('1.10.0', false, null::text),
+            ('1.9.0', true, null),
+            ('2.0.0', true, 'broken release')
+    $$,
+    'Snapshots 1.9.0 and 2.0.0 should have been yanked'
+);
+select is(
+    (select latest_version from package where package_id = :'package1ID'),
+    '1.10.0',
+    'package1 latest version should have been updated to 1.10.0'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(54);
+select plan(84);
 
 -- Check default_text_search_config is correct
 select results_eq(
@@ -14,6 +14,7 @@ select has_extension('pgcrypto');
 
 -- Check expected tables exist
 select tables_are(array[
+    'chart_archive',
     'chart_repository',
     'email_verification_code',
     'image',
@@ -25,6 +26,9 @@ select tables_are(array[
     'package_kind',
     'session',
     'snapshot',
+    'tracking_error',
+    'tracking_request',
+    'tracking_run',
     'user',
     'user__organization',
     'version_functions',
@@ -32,6 +36,11 @@ select tables_are(array[
 ]);
 
 -- Check tables have expected columns
+select columns_are('chart_archive', array[
+    'digest',
+    'data',
+    'created_at'
+]);
 select columns_are('chart_repository', array[
     'chart_repository_id',
     'name',
@@ -40,7 +49,15 @@ select columns_are('chart_repository', array[
     'last_tracking_ts',
     'last_tracking_errors',
     'user_id',
-    'organization_id'
+    'organization_id',
+    'credentials',
+    'tracking_interval',
+    'tracking_jitter',
+    'next_tracking_ts',
+    'index_cache',
+    'webhook_secret',
+    'keyring',
+    'hosted'
 ]);
 select columns_are('email_verification_code', array[
     'email_verification_code_id',
@@ -111,7 +128,26 @@ select columns_are('snapshot', array[
     'digest',
     'readme',
     'links',
-    'data'
+    'data',
+    'default_values',
+    'values_schema',
+    'signed',
+    'signature_verified',
+    'signer',
+    'dependencies',
+    'container_images',
+    'kube_version',
+    'kube_version_ranges',
+    'deprecated_apis',
+    'lint_report',
+    'quality_score',
+    'content_url',
+    'values_docs',
+    'archive_digest',
+    'chart_metadata',
+    'provenance_digest',
+    'yanked',
+    'yanked_reason'
 ]);
 select columns_are('user', array[
     'user_id',
@@ -123,6 +159,32 @@ select columns_are('user', array[
     'password',
     'created_at'
 ]);
+select columns_are('tracking_error', array[
+    'tracking_error_id',
+    'tracking_run_id',
+    'kind',
+    'chart_name',
+    'chart_version',
+    'url',
+    'message'
+]);
+select columns_are('tracking_request', array[
+    'tracking_request_id',
+    'chart_repository_id',
+    'status',
+    'created_at',
+    'completed_at',
+    'tracking_run_id'
+]);
+select columns_are('tracking_run', array[
+    'tracking_run_id',
+    'chart_repository_id',
+    'started_at',
+    'finished_at',
+    'processed',
+    'registered',
+    'failed'
+]);
 select columns_are('user__organization', array[
     'user_id',
     'organization_id',
@@ -136,10 +198,14 @@ select columns_are('version_schema', array[
 ]);
 
 -- Check tables have expected indexes
+select indexes_are('chart_archive', array[
+    'chart_archive_pkey'
+]);
 select indexes_are('chart_repository', array[
     'chart_repository_pkey',
     'chart_repository_name_key',
-    'chart_repository_url_key'
+    'chart_repository_url_key',
+    'chart_repository_next_tracking_ts_idx'
 ]);
 select indexes_are('maintainer', array[
     'maintainer_pkey',
@@ -163,7 +229,20 @@ select indexes_are('package_kind', array[
 ]);
 select indexes_are('snapshot', array[
     'snapshot_pkey',
-    'snapshot_digest_key'
+    'snapshot_digest_key',
+    'snapshot_dependencies_idx'
+]);
+select indexes_are('tracking_error', array[
+    'tracking_error_pkey',
+    'tracking_error_tracking_run_id_idx'
+]);
+select indexes_are('tracking_request', array[
+    'tracking_request_pkey',
+    'tracking_request_chart_repository_id_idx'
+]);
+select indexes_are('tracking_run', array[
+    'tracking_run_pkey',
+    'tracking_run_chart_repository_id_idx'
 ]);
 
 -- Check expected functions exist
@@ -184,25 +263,48 @@ select has_function('register_user');
 select has_function('verify_email');
 
 select has_function('get_package');
+select has_function('get_package_content');
+select has_function('get_package_dependents');
+select has_function('get_package_values');
+select has_function('get_package_values_docs');
+select has_function('get_package_values_schema');
 select has_function('get_packages_stats');
 select has_function('get_packages_updates');
 select has_function('register_package');
 select has_function('search_packages');
 select has_function('semver_gte');
+select has_function('unregister_package');
+select has_function('unyank_package_version');
+select has_function('update_package_latest_version');
+select has_function('yank_package_version');
 
 
 select has_function('add_chart_repository');
+select has_function('add_tracking_request');
 select has_function('delete_chart_repository');
+select has_function('delete_chart_repository_webhook_secret');
 select has_function('get_chart_repositories');
+select has_function('get_chart_repositories_due_for_tracking');
 select has_function('get_chart_repository_by_name');
 select has_function('get_chart_repository_packages_digest');
+select has_function('get_chart_repository_tracking_request');
+select has_function('get_chart_repository_tracking_run_errors');
+select has_function('get_chart_repository_tracking_runs');
+select has_function('get_mirror_index_entries');
 select has_function('get_org_chart_repositories');
 select has_function('get_user_chart_repositories');
+select has_function('register_tracking_run');
+select has_function('request_chart_repository_tracking');
+select has_function('set_chart_repository_webhook_secret');
 select has_function('update_chart_repository');
+select has_function('user_has_access_to_chart_repository');
 
 select has_function('get_image');
 select has_function('register_image');
 
+select has_function('get_chart_archive');
+select has_function('register_chart_archive');
+
 -- Check package kinds exist
 select results_eq(
     'select * from package_kind',
@@ -24,14 +24,14 @@ type API struct {
 }
 
 // New creates a new API instance.
-func New(db hub.DB, es hub.EmailSender) *API {
+func New(db hub.DB, es hub.EmailSender, enc hub.Encrypter) *API {
 	return &API{
 		db: db,
 
 		Organizations:     org.NewManager(db, es),
 		User:              user.NewManager(db, es),
 		Packages:          pkg.NewManager(db),
-		ChartRepositories: chartrepo.NewManager(db),
+		ChartRepositories: chartrepo.NewManager(db, enc),
 	}
 }
 
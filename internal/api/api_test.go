@@ -11,7 +11,7 @@ import (
 
 func TestCheckAvailability(t *testing.T) {
 	t.Run("resource kind not supported", func(t *testing.T) {
-		a := New(nil, nil)
+		a := New(nil, nil, nil)
 		_, err := a.CheckAvailability(context.Background(), "invalidKind", "value")
 		assert.Error(t, err)
 	})
@@ -49,7 +49,7 @@ func TestCheckAvailability(t *testing.T) {
 				tc.dbQuery = fmt.Sprintf("select not exists (%s)", tc.dbQuery)
 				db := &tests.DBMock{}
 				db.On("QueryRow", tc.dbQuery, "value").Return(tc.available, nil)
-				a := New(db, nil)
+				a := New(db, nil, nil)
 
 				available, err := a.CheckAvailability(context.Background(), tc.resourceKind, "value")
 				assert.NoError(t, err)
@@ -63,7 +63,7 @@ func TestCheckAvailability(t *testing.T) {
 		db := &tests.DBMock{}
 		dbQuery := `select not exists (select user_id from "user" where alias = $1)`
 		db.On("QueryRow", dbQuery, "value").Return(false, tests.ErrFakeDatabaseFailure)
-		a := New(db, nil)
+		a := New(db, nil, nil)
 
 		available, err := a.CheckAvailability(context.Background(), "userAlias", "value")
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -0,0 +1,15 @@
+package archive
+
+import "context"
+
+// Store describes the methods an archive.Store implementation must provide.
+type Store interface {
+	// SaveArchive stores a chart archive returning its digest.
+	SaveArchive(ctx context.Context, data []byte) (digest string, err error)
+}
+
+// Getter describes the methods an archive.Getter implementation must provide.
+type Getter interface {
+	// GetArchive returns the chart archive identified by the digest provided.
+	GetArchive(ctx context.Context, digest string) ([]byte, error)
+}
@@ -0,0 +1,51 @@
+package pg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// DB defines the methods the database handler must provide.
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// ArchiveStore is an archive.Store (and archive.Getter) implementation that uses PostgreSQL as the
+// underlying storage. Archives are identified by the sha256 digest of their
+// content, so each archive is only stored once.
+type ArchiveStore struct {
+	db DB
+}
+
+// NewArchiveStore creates a new ArchiveStore instance.
+func NewArchiveStore(db DB) *ArchiveStore {
+	return &ArchiveStore{
+		db: db,
+	}
+}
+
+// SaveArchive implements the archive.Store interface.
+func (s *ArchiveStore) SaveArchive(ctx context.Context, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	_, err := s.db.Exec(ctx, "select register_chart_archive($1::text, $2::bytea)", digest, data)
+	if err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// GetArchive implements the archive.Getter interface.
+func (s *ArchiveStore) GetArchive(ctx context.Context, digest string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(ctx, "select get_chart_archive($1::text)", digest).Scan(&data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
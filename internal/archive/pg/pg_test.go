@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewArchiveStore(t *testing.T) {
+	db := &tests.DBMock{}
+	s := NewArchiveStore(db)
+
+	assert.IsType(t, &ArchiveStore{}, s)
+	assert.Equal(t, db, s.db)
+}
+
+func TestSaveArchive(t *testing.T) {
+	data := []byte("archive")
+	digest := "0eb3e36bfb24dcd9bb1d1bece1531216b59539a8fde17ee80224af0653c92aa3"
+	dbQuery := "select register_chart_archive($1::text, $2::bytea)"
+
+	t.Run("successful archive registration", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, digest, data).Return(nil)
+		s := NewArchiveStore(db)
+
+		archiveDigest, err := s.SaveArchive(context.Background(), data)
+		require.NoError(t, err)
+		assert.Equal(t, digest, archiveDigest)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, digest, data).Return(tests.ErrFakeDatabaseFailure)
+		s := NewArchiveStore(db)
+
+		archiveDigest, err := s.SaveArchive(context.Background(), data)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Empty(t, archiveDigest)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetArchive(t *testing.T) {
+	dbQuery := "select get_chart_archive($1::text)"
+
+	t.Run("existing archive", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "digest").Return([]byte("archive"), nil)
+		s := NewArchiveStore(db)
+
+		data, err := s.GetArchive(context.Background(), "digest")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("archive"), data)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("non existing archive", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "digest").Return(nil, pgx.ErrNoRows)
+		s := NewArchiveStore(db)
+
+		data, err := s.GetArchive(context.Background(), "digest")
+		assert.Equal(t, pgx.ErrNoRows, err)
+		assert.Nil(t, data)
+		db.AssertExpectations(t)
+	})
+}
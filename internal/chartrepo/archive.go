@@ -0,0 +1,244 @@
+package chartrepo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/archive"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/oci"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// archiveHTTPTimeout represents the timeout used when downloading charts
+// archives. Archives are downloaded while serving requests, so it must be kept
+// well under the hub server write timeout.
+const archiveHTTPTimeout = 2 * time.Second
+
+var (
+	// ErrArchiveTooLarge indicates that the chart archive requested, or its
+	// content once decompressed, exceeds the maximum size allowed.
+	ErrArchiveTooLarge = errors.New("chart archive too large")
+
+	// ErrArchiveNotAvailable indicates that the chart archive requested is not
+	// available in the archive store and it cannot be downloaded, as it
+	// belongs to a hosted chart repository.
+	ErrArchiveNotAvailable = errors.New("chart archive not available")
+)
+
+// ArchiveLoader loads the files of charts archives from the archive store,
+// when they are available in it, or from their repositories, keeping those of
+// the most recently used archives in an in-memory cache.
+type ArchiveLoader struct {
+	ag             archive.Getter
+	maxArchiveSize int64
+	maxFilesSize   int64
+	maxCacheSize   int64
+
+	mu        sync.Mutex
+	cacheSize int64
+	ll        *list.List
+	entries   map[string]*list.Element
+}
+
+// archiveCacheEntry represents an entry of the archives cache.
+type archiveCacheEntry struct {
+	key   string
+	files []*loader.BufferedFile
+	size  int64
+}
+
+// NewArchiveLoader creates a new ArchiveLoader instance. Archives larger than
+// maxArchiveSize bytes, or whose files exceed maxFilesSize bytes once
+// decompressed, won't be loaded, and the cache will keep the files of the
+// most recently used archives until their size exceeds maxCacheSize bytes.
+// The archive getter provided is optional.
+func NewArchiveLoader(ag archive.Getter, maxArchiveSize, maxFilesSize, maxCacheSize int64) *ArchiveLoader {
+	return &ArchiveLoader{
+		ag:             ag,
+		maxArchiveSize: maxArchiveSize,
+		maxFilesSize:   maxFilesSize,
+		maxCacheSize:   maxCacheSize,
+		ll:             list.New(),
+		entries:        make(map[string]*list.Element),
+	}
+}
+
+// Load returns the files of the chart archive located at the url provided,
+// sorted by name, when it is not available in the cache. Archives available
+// in the archive store (those with an archive digest) are read from it, the
+// rest are downloaded from the chart repository given, except for the ones of
+// hosted chart repositories (ErrArchiveNotAvailable). The digest of the
+// archive is used as part of the cache key, so that archives replaced in the
+// repository are reloaded.
+func (l *ArchiveLoader) Load(
+	ctx context.Context,
+	r *hub.ChartRepository,
+	u string,
+	digest string,
+	archiveDigest string,
+) ([]*loader.BufferedFile, error) {
+	key := u + "@" + digest
+	if files, ok := l.get(key); ok {
+		return files, nil
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case archiveDigest != "" && l.ag != nil:
+		data, err = l.ag.GetArchive(ctx, archiveDigest)
+		if err == nil && int64(len(data)) > l.maxArchiveSize {
+			err = ErrArchiveTooLarge
+		}
+	case r.Hosted:
+		// Hosted charts archives are served by the hub itself from the
+		// archive store, so they are never downloaded
+		err = ErrArchiveNotAvailable
+	default:
+		data, err = l.download(ctx, r, u)
+	}
+	if err != nil {
+		return nil, err
+	}
+	files, err := LoadArchiveFiles(data, l.maxFilesSize)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	var size int64
+	for _, f := range files {
+		size += int64(len(f.Data))
+	}
+	l.add(&archiveCacheEntry{key: key, files: files, size: size})
+
+	return files, nil
+}
+
+// LoadArchiveFiles loads the files of the chart archive provided, checking
+// first that they do not exceed maxFilesSize bytes once decompressed.
+func LoadArchiveFiles(data []byte, maxFilesSize int64) ([]*loader.BufferedFile, error) {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+	n, err := io.Copy(ioutil.Discard, io.LimitReader(gzr, maxFilesSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > maxFilesSize {
+		return nil, ErrArchiveTooLarge
+	}
+	return loader.LoadArchiveFiles(bytes.NewReader(data))
+}
+
+// download downloads the chart archive located at the url provided from the
+// chart repository given, pulling it from the registry in the case of OCI
+// repositories.
+func (l *ArchiveLoader) download(ctx context.Context, r *hub.ChartRepository, u string) ([]byte, error) {
+	httpClient, err := NewHTTPClient(r, archiveHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	if oci.IsOCI(u) {
+		ref, err := oci.ParseReference(u)
+		if err != nil {
+			return nil, err
+		}
+		// Credentials are only sent to the chart repository's registry
+		var username, password string
+		if r.Credentials != nil && isRepositoryHost(r, ref.Registry) {
+			username, password = r.Credentials.Username, r.Credentials.Password
+		}
+		body, err = oci.NewClient(httpClient, username, password).PullBlob(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := httpClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+		}
+		body = resp.Body
+	}
+	defer body.Close()
+
+	archive, err := ioutil.ReadAll(io.LimitReader(body, l.maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(archive)) > l.maxArchiveSize {
+		return nil, ErrArchiveTooLarge
+	}
+	return archive, nil
+}
+
+// isRepositoryHost checks if the host provided is the one of the chart
+// repository given.
+func isRepositoryHost(r *hub.ChartRepository, host string) bool {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return false
+	}
+	return u.Host == host
+}
+
+// get returns the files of the archive identified by the key provided from
+// the cache, marking it as the most recently used.
+func (l *ArchiveLoader) get(key string) ([]*loader.BufferedFile, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.ll.MoveToFront(e)
+	return e.Value.(*archiveCacheEntry).files, true
+}
+
+// add adds the entry provided to the cache, evicting the least recently used
+// entries when needed to keep the cache size under the limit. Entries larger
+// than the limit are not cached.
+func (l *ArchiveLoader) add(entry *archiveCacheEntry) {
+	if entry.size > l.maxCacheSize {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.entries[entry.key]; ok {
+		return
+	}
+	l.entries[entry.key] = l.ll.PushFront(entry)
+	l.cacheSize += entry.size
+	for l.cacheSize > l.maxCacheSize {
+		oldest := l.ll.Back()
+		oldestEntry := oldest.Value.(*archiveCacheEntry)
+		l.ll.Remove(oldest)
+		delete(l.entries, oldestEntry.key)
+		l.cacheSize -= oldestEntry.size
+	}
+}
@@ -0,0 +1,181 @@
+package chartrepo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/archive/pg"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestArchiveLoader(t *testing.T) {
+	ctx := context.Background()
+	archive := newTestChartArchive(t)
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/chart-1.0.0.tgz":
+			_, _ = w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	r := &hub.ChartRepository{URL: s.URL}
+
+	t.Run("archive not found", func(t *testing.T) {
+		l := NewArchiveLoader(nil, 1024*1024, 1024*1024, 1024*1024)
+		files, err := l.Load(ctx, r, s.URL+"/not-found.tgz", "digest", "")
+		assert.Error(t, err)
+		assert.Nil(t, files)
+	})
+
+	t.Run("archive too large", func(t *testing.T) {
+		l := NewArchiveLoader(nil, 10, 1024*1024, 1024*1024)
+		files, err := l.Load(ctx, r, s.URL+"/chart-1.0.0.tgz", "digest", "")
+		assert.True(t, errors.Is(err, ErrArchiveTooLarge))
+		assert.Nil(t, files)
+	})
+
+	t.Run("archive files too large once decompressed", func(t *testing.T) {
+		l := NewArchiveLoader(nil, 1024*1024, 10, 1024*1024)
+		files, err := l.Load(ctx, r, s.URL+"/chart-1.0.0.tgz", "digest", "")
+		assert.True(t, errors.Is(err, ErrArchiveTooLarge))
+		assert.Nil(t, files)
+	})
+
+	t.Run("archive loaded from the archive store", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", "select get_chart_archive($1::text)", "archiveDigest").Return(archive, nil)
+		l := NewArchiveLoader(pg.NewArchiveStore(db), 1024*1024, 1024*1024, 1024*1024)
+		requests = 0
+		files, err := l.Load(ctx, r, s.URL+"/not-found.tgz", "digest", "archiveDigest")
+		require.NoError(t, err)
+		assert.Len(t, files, 3)
+		assert.Equal(t, 0, requests)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart repository archive never downloaded", func(t *testing.T) {
+		l := NewArchiveLoader(nil, 1024*1024, 1024*1024, 1024*1024)
+		requests = 0
+		files, err := l.Load(ctx, &hub.ChartRepository{URL: s.URL, Hosted: true}, s.URL+"/chart-1.0.0.tgz", "digest", "")
+		assert.True(t, errors.Is(err, ErrArchiveNotAvailable))
+		assert.Nil(t, files)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("credentials only sent to the chart repository host", func(t *testing.T) {
+		var authorization string
+		s2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			_, _ = w.Write(archive)
+		}))
+		defer s2.Close()
+		r := &hub.ChartRepository{
+			URL: s.URL,
+			Credentials: &hub.ChartRepositoryCredentials{
+				Username: "user",
+				Password: "pass",
+			},
+		}
+		l := NewArchiveLoader(nil, 1024*1024, 1024*1024, 1024*1024)
+		_, err := l.Load(ctx, r, s2.URL+"/chart-1.0.0.tgz", "digest", "")
+		require.NoError(t, err)
+		assert.Empty(t, authorization)
+		assert.False(t, isRepositoryHost(r, "registry.io"))
+		assert.True(t, isRepositoryHost(&hub.ChartRepository{URL: "oci://registry.io/charts"}, "registry.io"))
+	})
+
+	t.Run("archive loaded and cached", func(t *testing.T) {
+		l := NewArchiveLoader(nil, 1024*1024, 1024*1024, 1024*1024)
+		requests = 0
+		for i := 0; i < 2; i++ {
+			files, err := l.Load(ctx, r, s.URL+"/chart-1.0.0.tgz", "digest", "")
+			require.NoError(t, err)
+			names := make([]string, 0, len(files))
+			for _, f := range files {
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, []string{"Chart.yaml", "templates/NOTES.txt", "values.yaml"}, names)
+		}
+		assert.Equal(t, 1, requests)
+
+		// A different digest means a different archive
+		_, err := l.Load(ctx, r, s.URL+"/chart-1.0.0.tgz", "digest2", "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("least recently used archives evicted", func(t *testing.T) {
+		// Cache with room for a single archive
+		l := NewArchiveLoader(nil, 1024*1024, 1024*1024, 1024*1024)
+		_, err := l.Load(ctx, r, s.URL+"/chart-1.0.0.tgz", "digest", "")
+		require.NoError(t, err)
+		l = NewArchiveLoader(nil, 1024*1024, 1024*1024, l.cacheSize)
+		requests = 0
+		for _, digest := range []string{"digest1", "digest2", "digest1"} {
+			_, err := l.Load(ctx, r, s.URL+"/chart-1.0.0.tgz", digest, "")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, requests)
+		assert.Len(t, l.entries, 1)
+	})
+}
+
+func newTestChartArchive(t *testing.T) []byte {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "chart",
+			Version:    "1.0.0",
+		},
+		Raw: []*chart.File{
+			{Name: "values.yaml", Data: []byte("key: value\n")},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/NOTES.txt", Data: []byte("Thanks for installing {{ .Chart.Name }}\n")},
+		},
+	}
+	dir, err := ioutil.TempDir("", "artifacthub-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	archivePath, err := chartutil.Save(c, dir)
+	require.NoError(t, err)
+	archive, err := ioutil.ReadFile(archivePath)
+	require.NoError(t, err)
+	return archive
+}
+
+func TestLoadArchiveFiles(t *testing.T) {
+	archive := newTestChartArchive(t)
+
+	t.Run("invalid archive", func(t *testing.T) {
+		files, err := LoadArchiveFiles([]byte("invalid"), 1024*1024)
+		assert.Error(t, err)
+		assert.Nil(t, files)
+	})
+
+	t.Run("archive files too large once decompressed", func(t *testing.T) {
+		files, err := LoadArchiveFiles(archive, 10)
+		assert.True(t, errors.Is(err, ErrArchiveTooLarge))
+		assert.Nil(t, files)
+	})
+
+	t.Run("archive files loaded", func(t *testing.T) {
+		files, err := LoadArchiveFiles(archive, 1024*1024)
+		require.NoError(t, err)
+		assert.NotEmpty(t, files)
+	})
+}
@@ -0,0 +1,89 @@
+package chartrepo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+)
+
+// NewHTTPClient returns an http client ready to access the chart repository
+// provided, using its credentials and TLS certificates when available.
+// Credentials are only sent in requests to the chart repository's host.
+func NewHTTPClient(r *hub.ChartRepository, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+	creds := r.Credentials
+	if creds == nil {
+		return client, nil
+	}
+
+	// TLS certificates
+	if creds.CACert != "" || creds.ClientCert != "" {
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+		if creds.CACert != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM([]byte(creds.CACert)) {
+				return nil, errors.New("invalid ca certificate")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if creds.ClientCert != "" {
+			cert, err := tls.X509KeyPair([]byte(creds.ClientCert), []byte(creds.ClientKey))
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	// Authentication
+	if creds.Token != "" || creds.Username != "" {
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = &authTransport{
+			base:  transport,
+			host:  u.Host,
+			creds: creds,
+		}
+	}
+
+	return client, nil
+}
+
+// authTransport is an http.RoundTripper that adds the credentials provided to
+// the requests sent to the host given, unless they already have an
+// Authorization header set.
+type authTransport struct {
+	base  http.RoundTripper
+	host  string
+	creds *hub.ChartRepositoryCredentials
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == t.host && req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		if t.creds.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+t.creds.Token)
+		} else {
+			req.SetBasicAuth(t.creds.Username, t.creds.Password)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,51 @@
+package chartrepo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("invalid ca certificate", func(t *testing.T) {
+		r := &hub.ChartRepository{
+			URL:         "https://repo.url",
+			Credentials: &hub.ChartRepositoryCredentials{CACert: "invalid"},
+		}
+		_, err := NewHTTPClient(r, time.Second)
+		assert.Error(t, err)
+	})
+
+	t.Run("credentials only sent to the repository host", func(t *testing.T) {
+		var authHeader string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader = r.Header.Get("Authorization")
+		}))
+		defer s.Close()
+
+		testCases := []struct {
+			repoURL            string
+			creds              *hub.ChartRepositoryCredentials
+			expectedAuthHeader string
+		}{
+			{s.URL, nil, ""},
+			{s.URL, &hub.ChartRepositoryCredentials{Token: "token"}, "Bearer token"},
+			{s.URL, &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"}, "Basic dXNlcjpwYXNz"},
+			{"https://other.host", &hub.ChartRepositoryCredentials{Token: "token"}, ""},
+		}
+		for _, tc := range testCases {
+			r := &hub.ChartRepository{URL: tc.repoURL, Credentials: tc.creds}
+			client, err := NewHTTPClient(r, time.Second)
+			require.NoError(t, err)
+			resp, err := client.Get(s.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.expectedAuthHeader, authHeader)
+		}
+	})
+}
@@ -2,20 +2,43 @@ package chartrepo
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/jackc/pgconn"
 )
 
+var (
+	// ErrEncrypterNotAvailable indicates that the chart repository
+	// credentials cannot be processed because no encrypter has been
+	// configured.
+	ErrEncrypterNotAvailable = errors.New("encrypter not available")
+
+	// ErrHostedChartRepository indicates that the operation requested is not
+	// available for hosted chart repositories, as they are not tracked.
+	ErrHostedChartRepository = errors.New("hosted chart repositories cannot be tracked")
+)
+
+// hostedChartRepositoryErrCode represents the error code raised by the
+// database functions when an operation not available for hosted chart
+// repositories is requested for one of them.
+const hostedChartRepositoryErrCode = "55000"
+
 // Manager provides an API to manage chart repositories.
 type Manager struct {
-	db hub.DB
+	db  hub.DB
+	enc hub.Encrypter
 }
 
 // NewManager creates a new Manager instance.
-func NewManager(db hub.DB) *Manager {
+func NewManager(db hub.DB, enc hub.Encrypter) *Manager {
 	return &Manager{
-		db: db,
+		db:  db,
+		enc: enc,
 	}
 }
 
@@ -23,11 +46,23 @@ func NewManager(db hub.DB) *Manager {
 func (m *Manager) Add(ctx context.Context, orgName string, r *hub.ChartRepository) error {
 	query := "select add_chart_repository($1::uuid, $2::text, $3::jsonb)"
 	userID := ctx.Value(hub.UserIDKey).(string)
-	rJSON, _ := json.Marshal(r)
-	_, err := m.db.Exec(ctx, query, userID, orgName, rJSON)
+	rJSON, err := m.marshalChartRepository(r)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(ctx, query, userID, orgName, rJSON)
 	return err
 }
 
+// AddTrackingRequest registers a request to track the chart repository
+// identified by the id provided as soon as possible. If the chart repository
+// has a tracking request pending already, no new request is registered.
+func (m *Manager) AddTrackingRequest(ctx context.Context, chartRepositoryID string) error {
+	query := "select add_tracking_request($1::uuid)"
+	_, err := m.db.Exec(ctx, query, chartRepositoryID)
+	return checkHostedChartRepositoryErr(err)
+}
+
 // Delete deletes the provided chart repository from the database.
 func (m *Manager) Delete(ctx context.Context, name string) error {
 	query := "select delete_chart_repository($1::uuid, $2::text)"
@@ -36,18 +71,81 @@ func (m *Manager) Delete(ctx context.Context, name string) error {
 	return err
 }
 
-// GetAll returns all available chart repositories.
+// DeleteWebhookSecret deletes the webhook secret of the chart repository
+// provided, disabling its webhook. The user doing the request must own the
+// chart repository or belong to the organization which owns it.
+func (m *Manager) DeleteWebhookSecret(ctx context.Context, name string) error {
+	query := "select delete_chart_repository_webhook_secret($1::uuid, $2::text)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	_, err := m.db.Exec(ctx, query, userID, name)
+	return err
+}
+
+// GenerateWebhookSecret generates a new webhook secret for the chart
+// repository provided, replacing the existing one if any. The secret is
+// stored encrypted in the database and returned along with the chart
+// repository id as a json object. The user doing the request must own the
+// chart repository or belong to the organization which owns it.
+func (m *Manager) GenerateWebhookSecret(ctx context.Context, name string) ([]byte, error) {
+	query := "select set_chart_repository_webhook_secret($1::uuid, $2::text, $3::text)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	if m.enc == nil {
+		return nil, ErrEncrypterNotAvailable
+	}
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, err
+	}
+	secret := hex.EncodeToString(randomBytes)
+	encryptedSecret, err := m.enc.Encrypt([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+	encodedSecret := base64.StdEncoding.EncodeToString(encryptedSecret)
+	var chartRepositoryID string
+	if err := m.db.QueryRow(ctx, query, userID, name, encodedSecret).Scan(&chartRepositoryID); err != nil {
+		return nil, checkHostedChartRepositoryErr(err)
+	}
+	return json.Marshal(map[string]string{
+		"chart_repository_id": chartRepositoryID,
+		"secret":              secret,
+	})
+}
+
+// GetAll returns all available chart repositories, including their
+// credentials when available.
 func (m *Manager) GetAll(ctx context.Context) ([]*hub.ChartRepository, error) {
-	var r []*hub.ChartRepository
-	err := m.dbQueryUnmarshal(ctx, &r, "select get_chart_repositories()")
-	return r, err
+	return m.getChartRepositories(ctx, "select get_chart_repositories()")
 }
 
-// GetByName returns the chart repository identified by the name provided.
+// GetByName returns the chart repository identified by the name provided,
+// including its credentials when available.
 func (m *Manager) GetByName(ctx context.Context, name string) (*hub.ChartRepository, error) {
-	var r *hub.ChartRepository
-	err := m.dbQueryUnmarshal(ctx, &r, "select get_chart_repository_by_name($1::text)", name)
-	return r, err
+	var dbr *chartRepositoryDB
+	err := m.dbQueryUnmarshal(ctx, &dbr, "select get_chart_repository_by_name($1::text)", name)
+	if err != nil {
+		return nil, err
+	}
+	return m.decryptCredentials(dbr)
+}
+
+// GetDueForTracking returns the chart repositories whose next tracking is due,
+// including their credentials when available.
+func (m *Manager) GetDueForTracking(ctx context.Context) ([]*hub.ChartRepository, error) {
+	return m.getChartRepositories(ctx, "select get_chart_repositories_due_for_tracking()")
+}
+
+// GetMirrorIndexEntries returns the charts versions available in the archive
+// mirror for the chart repository identified by the name provided. When no
+// name is provided, the entries of all chart repositories are returned.
+func (m *Manager) GetMirrorIndexEntries(ctx context.Context, name string) ([]*MirrorIndexEntry, error) {
+	var nameArg *string
+	if name != "" {
+		nameArg = &name
+	}
+	var entries []*MirrorIndexEntry
+	err := m.dbQueryUnmarshal(ctx, &entries, "select get_mirror_index_entries($1::text)", nameArg)
+	return entries, err
 }
 
 // GetPackagesDigest returns the digests for all packages in the repository
@@ -78,13 +176,125 @@ func (m *Manager) GetOwnedByUserJSON(ctx context.Context) ([]byte, error) {
 	return m.dbQueryJSON(ctx, query, userID)
 }
 
+// GetTrackingRequestJSON returns the tracking request provided of the given
+// chart repository. The user doing the request must own the chart repository
+// or belong to the organization which owns it.
+func (m *Manager) GetTrackingRequestJSON(ctx context.Context, name, trackingRequestID string) ([]byte, error) {
+	query := "select get_chart_repository_tracking_request($1::uuid, $2::text, $3::uuid)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	return m.dbQueryJSON(ctx, query, userID, name, trackingRequestID)
+}
+
+// GetTrackingRunErrorsJSON returns the errors found during the tracking run
+// provided of the given chart repository. The user doing the request must own
+// the chart repository or belong to the organization which owns it.
+func (m *Manager) GetTrackingRunErrorsJSON(ctx context.Context, name, trackingRunID string) ([]byte, error) {
+	query := "select get_chart_repository_tracking_run_errors($1::uuid, $2::text, $3::uuid)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	return m.dbQueryJSON(ctx, query, userID, name, trackingRunID)
+}
+
+// GetTrackingRunsJSON returns the most recent tracking runs of the chart
+// repository provided. The user doing the request must own the chart
+// repository or belong to the organization which owns it.
+func (m *Manager) GetTrackingRunsJSON(ctx context.Context, name string) ([]byte, error) {
+	query := "select get_chart_repository_tracking_runs($1::uuid, $2::text)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	return m.dbQueryJSON(ctx, query, userID, name)
+}
+
+// GetWebhookSecret returns the webhook secret of the chart repository
+// identified by the id provided once it has been decrypted. An empty string
+// is returned when the chart repository has no webhook secret. Hosted chart
+// repositories are not tracked, so they are never found.
+func (m *Manager) GetWebhookSecret(ctx context.Context, chartRepositoryID string) (string, error) {
+	query := `
+	select coalesce(webhook_secret, '')
+	from chart_repository
+	where chart_repository_id = $1::uuid
+	and hosted = false`
+	var encodedSecret string
+	if err := m.db.QueryRow(ctx, query, chartRepositoryID).Scan(&encodedSecret); err != nil {
+		return "", err
+	}
+	if encodedSecret == "" {
+		return "", nil
+	}
+	if m.enc == nil {
+		return "", ErrEncrypterNotAvailable
+	}
+	encryptedSecret, err := base64.StdEncoding.DecodeString(encodedSecret)
+	if err != nil {
+		return "", err
+	}
+	secret, err := m.enc.Decrypt(encryptedSecret)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// HasAccess checks if the user doing the request owns the chart repository
+// provided or belongs to the organization which owns it.
+func (m *Manager) HasAccess(ctx context.Context, name string) (bool, error) {
+	query := "select user_has_access_to_chart_repository($1::uuid, $2::text)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	var hasAccess bool
+	if err := m.db.QueryRow(ctx, query, userID, name).Scan(&hasAccess); err != nil {
+		return false, err
+	}
+	return hasAccess, nil
+}
+
+// RegisterTrackingRun registers the tracking run provided in the database.
+func (m *Manager) RegisterTrackingRun(ctx context.Context, run *hub.TrackingRun) error {
+	query := "select register_tracking_run($1::jsonb)"
+	runJSON, _ := json.Marshal(run)
+	_, err := m.db.Exec(ctx, query, runJSON)
+	return err
+}
+
+// RequestTracking requests the tracking of the chart repository provided as
+// soon as possible, returning the tracking request as a json object. Requests
+// are handled on the next poll when the chart tracker runs in daemon mode, or
+// the next time it runs otherwise. The user doing the request must own the
+// chart repository or belong to the organization which owns it. Hosted chart
+// repositories cannot be tracked (ErrHostedChartRepository).
+func (m *Manager) RequestTracking(ctx context.Context, name string) ([]byte, error) {
+	query := "select request_chart_repository_tracking($1::uuid, $2::text)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	jsonData, err := m.dbQueryJSON(ctx, query, userID, name)
+	if err != nil {
+		return nil, checkHostedChartRepositoryErr(err)
+	}
+	return jsonData, nil
+}
+
+// SetIndexCache stores the index file cache information provided for the
+// chart repository identified by the id given.
+func (m *Manager) SetIndexCache(
+	ctx context.Context,
+	chartRepositoryID string,
+	cache *hub.ChartRepositoryIndexCache,
+) error {
+	query := "update chart_repository set index_cache = $2 where chart_repository_id = $1"
+	cacheJSON, _ := json.Marshal(cache)
+	_, err := m.db.Exec(ctx, query, chartRepositoryID, cacheJSON)
+	return err
+}
+
 // SetLastTrackingResults updates the timestamp and errors of the last tracking
-// of the provided repository in the database.
+// of the provided repository in the database. The next tracking of the
+// repository is scheduled based on its tracking interval and jitter.
 func (m *Manager) SetLastTrackingResults(ctx context.Context, chartRepositoryID, errs string) error {
 	query := `
 	update chart_repository set
 		last_tracking_ts = current_timestamp,
-		last_tracking_errors = nullif($2, '')
+		last_tracking_errors = nullif($2, ''),
+		next_tracking_ts = current_timestamp + make_interval(
+			mins => tracking_interval,
+			secs => floor(random() * tracking_jitter * 60)
+		)
 	where chart_repository_id = $1`
 	_, err := m.db.Exec(ctx, query, chartRepositoryID, errs)
 	return err
@@ -94,11 +304,89 @@ func (m *Manager) SetLastTrackingResults(ctx context.Context, chartRepositoryID,
 func (m *Manager) Update(ctx context.Context, r *hub.ChartRepository) error {
 	query := "select update_chart_repository($1::uuid, $2::jsonb)"
 	userID := ctx.Value(hub.UserIDKey).(string)
-	rJSON, _ := json.Marshal(r)
-	_, err := m.db.Exec(ctx, query, userID, rJSON)
+	rJSON, err := m.marshalChartRepository(r)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec(ctx, query, userID, rJSON)
 	return err
 }
 
+// marshalChartRepository returns the json representation of the chart
+// repository provided as expected by the database, encrypting its credentials
+// when available. Empty credentials are sent as an empty string, indicating
+// that any existing credentials should be removed.
+func (m *Manager) marshalChartRepository(r *hub.ChartRepository) ([]byte, error) {
+	dbr := &chartRepositoryDB{ChartRepository: r}
+	if r.Credentials != nil {
+		var encodedCredentials string
+		if *r.Credentials != (hub.ChartRepositoryCredentials{}) {
+			if m.enc == nil {
+				return nil, ErrEncrypterNotAvailable
+			}
+			credentialsJSON, _ := json.Marshal(r.Credentials)
+			encryptedCredentials, err := m.enc.Encrypt(credentialsJSON)
+			if err != nil {
+				return nil, err
+			}
+			encodedCredentials = base64.StdEncoding.EncodeToString(encryptedCredentials)
+		}
+		dbr.Credentials = &encodedCredentials
+	}
+	return json.Marshal(dbr)
+}
+
+// getChartRepositories is a helper that executes the query provided and
+// returns the chart repositories returned from the database once their
+// credentials have been decrypted. Repositories whose credentials cannot be
+// decrypted are returned without them, with the error found set in their
+// CredentialsError field, so that one of them does not prevent the others
+// from being processed.
+func (m *Manager) getChartRepositories(ctx context.Context, query string) ([]*hub.ChartRepository, error) {
+	var dbRepos []*chartRepositoryDB
+	if err := m.dbQueryUnmarshal(ctx, &dbRepos, query); err != nil {
+		return nil, err
+	}
+	repos := make([]*hub.ChartRepository, 0, len(dbRepos))
+	for _, dbr := range dbRepos {
+		r, err := m.decryptCredentials(dbr)
+		if err != nil {
+			r = dbr.ChartRepository
+			r.Credentials = nil
+			r.CredentialsError = err
+		}
+		repos = append(repos, r)
+	}
+	return repos, nil
+}
+
+// decryptCredentials returns the chart repository provided once its
+// credentials have been decrypted.
+func (m *Manager) decryptCredentials(dbr *chartRepositoryDB) (*hub.ChartRepository, error) {
+	if dbr == nil {
+		return nil, nil
+	}
+	r := dbr.ChartRepository
+	if dbr.Credentials == nil || *dbr.Credentials == "" {
+		return r, nil
+	}
+	if m.enc == nil {
+		return nil, ErrEncrypterNotAvailable
+	}
+	encryptedCredentials, err := base64.StdEncoding.DecodeString(*dbr.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	credentialsJSON, err := m.enc.Decrypt(encryptedCredentials)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(credentialsJSON, &r.Credentials); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
 // dbQueryJSON is a helper that executes the query provided and returns a bytes
 // slice containing the json data returned from the database.
 func (m *Manager) dbQueryJSON(ctx context.Context, query string, args ...interface{}) ([]byte, error) {
@@ -121,3 +409,22 @@ func (m *Manager) dbQueryUnmarshal(ctx context.Context, v interface{}, query str
 	}
 	return nil
 }
+
+// chartRepositoryDB represents a chart repository as it is exchanged with the
+// database, with its credentials encrypted and base64 encoded.
+type chartRepositoryDB struct {
+	*hub.ChartRepository
+	Credentials *string `json:"credentials,omitempty"`
+}
+
+// checkHostedChartRepositoryErr returns ErrHostedChartRepository when the
+// error provided was raised by the database because the operation requested
+// is not available for hosted chart repositories. Otherwise the error given is
+// returned untouched.
+func checkHostedChartRepositoryErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == hostedChartRepositoryErrCode {
+		return ErrHostedChartRepository
+	}
+	return err
+}
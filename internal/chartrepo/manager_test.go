@@ -2,10 +2,15 @@ package chartrepo
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 
+	"github.com/artifacthub/hub/internal/encryption"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgconn"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -22,7 +27,7 @@ func TestAdd(t *testing.T) {
 	}
 
 	t.Run("user id not found in ctx", func(t *testing.T) {
-		m := NewManager(nil)
+		m := NewManager(nil, nil)
 		assert.Panics(t, func() {
 			_ = m.Add(context.Background(), "orgName", r)
 		})
@@ -31,7 +36,7 @@ func TestAdd(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "userID", "orgName", mock.Anything).Return(tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.Add(ctx, "orgName", r)
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -41,12 +46,78 @@ func TestAdd(t *testing.T) {
 	t.Run("add chart repository succeeded", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "userID", "orgName", mock.Anything).Return(nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.Add(ctx, "orgName", r)
 		assert.NoError(t, err)
 		db.AssertExpectations(t)
 	})
+
+	t.Run("credentials provided but encrypter not available", func(t *testing.T) {
+		m := NewManager(nil, nil)
+
+		err := m.Add(ctx, "orgName", &hub.ChartRepository{
+			Name:        "repo1",
+			URL:         "https://repo1.com",
+			Credentials: &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"},
+		})
+		assert.Equal(t, ErrEncrypterNotAvailable, err)
+	})
+
+	t.Run("add chart repository with credentials succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", "orgName", mock.MatchedBy(func(rJSON []byte) bool {
+			var dbr map[string]interface{}
+			_ = json.Unmarshal(rJSON, &dbr)
+			return dbr["credentials"] == base64.StdEncoding.EncodeToString([]byte("encrypted"))
+		})).Return(nil)
+		enc := &tests.EncrypterMock{}
+		enc.On("Encrypt", []byte(`{"username":"user","password":"pass"}`)).Return([]byte("encrypted"), nil)
+		m := NewManager(db, enc)
+
+		err := m.Add(ctx, "orgName", &hub.ChartRepository{
+			Name:        "repo1",
+			URL:         "https://repo1.com",
+			Credentials: &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"},
+		})
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+}
+
+func TestAddTrackingRequest(t *testing.T) {
+	dbQuery := "select add_tracking_request($1::uuid)"
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "repoID").Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		err := m.AddTrackingRequest(context.Background(), "repoID")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "repoID").Return(&pgconn.PgError{Code: hostedChartRepositoryErrCode})
+		m := NewManager(db, nil)
+
+		err := m.AddTrackingRequest(context.Background(), "repoID")
+		assert.Equal(t, ErrHostedChartRepository, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking request added successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "repoID").Return(nil)
+		m := NewManager(db, nil)
+
+		err := m.AddTrackingRequest(context.Background(), "repoID")
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
 }
 
 func TestDelete(t *testing.T) {
@@ -54,7 +125,7 @@ func TestDelete(t *testing.T) {
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
 
 	t.Run("user id not found in ctx", func(t *testing.T) {
-		m := NewManager(nil)
+		m := NewManager(nil, nil)
 		assert.Panics(t, func() {
 			_ = m.Delete(context.Background(), "repo1")
 		})
@@ -63,7 +134,7 @@ func TestDelete(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "userID", "repo1").Return(tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.Delete(ctx, "repo1")
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -73,7 +144,7 @@ func TestDelete(t *testing.T) {
 	t.Run("delete chart repository succeeded", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "userID", "repo1").Return(nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.Delete(ctx, "repo1")
 		assert.NoError(t, err)
@@ -81,6 +152,123 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestDeleteWebhookSecret(t *testing.T) {
+	dbQuery := "select delete_chart_repository_webhook_secret($1::uuid, $2::text)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_ = m.DeleteWebhookSecret(context.Background(), "repo1")
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", "repo1").Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		err := m.DeleteWebhookSecret(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("webhook secret deleted successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", "repo1").Return(nil)
+		m := NewManager(db, nil)
+
+		err := m.DeleteWebhookSecret(ctx, "repo1")
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	dbQuery := "select set_chart_repository_webhook_secret($1::uuid, $2::text, $3::text)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_, _ = m.GenerateWebhookSecret(context.Background(), "repo1")
+		})
+	})
+
+	t.Run("encrypter not available", func(t *testing.T) {
+		m := NewManager(nil, nil)
+
+		dataJSON, err := m.GenerateWebhookSecret(ctx, "repo1")
+		assert.Equal(t, ErrEncrypterNotAvailable, err)
+		assert.Nil(t, dataJSON)
+	})
+
+	t.Run("encrypter error", func(t *testing.T) {
+		enc := &tests.EncrypterMock{}
+		enc.On("Encrypt", mock.Anything).Return(nil, tests.ErrFakeEncrypterFailure)
+		m := NewManager(nil, enc)
+
+		dataJSON, err := m.GenerateWebhookSecret(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeEncrypterFailure, err)
+		assert.Nil(t, dataJSON)
+		enc.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", "ZW5jcnlwdGVk").Return(nil, tests.ErrFakeDatabaseFailure)
+		enc := &tests.EncrypterMock{}
+		enc.On("Encrypt", mock.Anything).Return([]byte("encrypted"), nil)
+		m := NewManager(db, enc)
+
+		dataJSON, err := m.GenerateWebhookSecret(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", "ZW5jcnlwdGVk").
+			Return(nil, &pgconn.PgError{Code: hostedChartRepositoryErrCode})
+		enc := &tests.EncrypterMock{}
+		enc.On("Encrypt", mock.Anything).Return([]byte("encrypted"), nil)
+		m := NewManager(db, enc)
+
+		dataJSON, err := m.GenerateWebhookSecret(ctx, "repo1")
+		assert.Equal(t, ErrHostedChartRepository, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+
+	t.Run("webhook secret generated and stored encrypted", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", mock.Anything).Return("repoID", nil)
+		e, err := encryption.NewEncrypter(encrypterConfig())
+		require.NoError(t, err)
+		m := NewManager(db, e)
+
+		dataJSON, err := m.GenerateWebhookSecret(ctx, "repo1")
+		require.NoError(t, err)
+		var webhook map[string]string
+		require.NoError(t, json.Unmarshal(dataJSON, &webhook))
+		assert.Equal(t, "repoID", webhook["chart_repository_id"])
+		assert.Len(t, webhook["secret"], 64)
+
+		storedSecret := db.Calls[0].Arguments.Get(3).(string)
+		assert.NotEqual(t, webhook["secret"], storedSecret)
+		assert.NotContains(t, storedSecret, webhook["secret"])
+		encryptedSecret, err := base64.StdEncoding.DecodeString(storedSecret)
+		require.NoError(t, err)
+		secret, err := e.Decrypt(encryptedSecret)
+		require.NoError(t, err)
+		assert.Equal(t, webhook["secret"], string(secret))
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetAll(t *testing.T) {
 	dbQuery := "select get_chart_repositories()"
 	db := &tests.DBMock{}
@@ -102,7 +290,7 @@ func TestGetAll(t *testing.T) {
         "url": "https://repo3.com"
     }]
 	`), nil)
-	m := NewManager(db)
+	m := NewManager(db, nil)
 
 	r, err := m.GetAll(context.Background())
 	require.NoError(t, err)
@@ -122,6 +310,72 @@ func TestGetAll(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+func TestGetDueForTracking(t *testing.T) {
+	dbQuery := "select get_chart_repositories_due_for_tracking()"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery).Return([]byte(`
+		[{
+			"chart_repository_id": "00000000-0000-0000-0000-000000000001",
+			"name": "repo1",
+			"display_name": "Repo 1",
+			"url": "https://repo1.com",
+			"tracking_interval": 60,
+			"tracking_jitter": 10
+		}]
+		`), nil)
+		m := NewManager(db, nil)
+
+		r, err := m.GetDueForTracking(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, r, 1)
+		assert.Equal(t, "00000000-0000-0000-0000-000000000001", r[0].ChartRepositoryID)
+		assert.Equal(t, "repo1", r[0].Name)
+		assert.Equal(t, 60, r[0].TrackingInterval)
+		assert.Equal(t, 10, r[0].TrackingJitter)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("repository credentials cannot be decrypted", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery).Return([]byte(`
+		[{
+			"chart_repository_id": "00000000-0000-0000-0000-000000000001",
+			"name": "repo1",
+			"credentials": "ZW5jcnlwdGVk"
+		}, {
+			"chart_repository_id": "00000000-0000-0000-0000-000000000002",
+			"name": "repo2"
+		}]
+		`), nil)
+		enc := &tests.EncrypterMock{}
+		enc.On("Decrypt", []byte("encrypted")).Return(nil, tests.ErrFakeEncrypterFailure)
+		m := NewManager(db, enc)
+
+		r, err := m.GetDueForTracking(context.Background())
+		require.NoError(t, err)
+		require.Len(t, r, 2)
+		assert.Equal(t, "repo1", r[0].Name)
+		assert.Nil(t, r[0].Credentials)
+		assert.Equal(t, tests.ErrFakeEncrypterFailure, r[0].CredentialsError)
+		assert.Equal(t, "repo2", r[1].Name)
+		assert.Nil(t, r[1].CredentialsError)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		_, err := m.GetDueForTracking(context.Background())
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetByName(t *testing.T) {
 	dbQuery := "select get_chart_repository_by_name($1::text)"
 
@@ -135,7 +389,7 @@ func TestGetByName(t *testing.T) {
 			"url": "https://repo1.com"
 		}
 		`), nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		r, err := m.GetByName(context.Background(), "repo1")
 		require.NoError(t, err)
@@ -146,10 +400,49 @@ func TestGetByName(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 
+	t.Run("get existing repository with credentials by name", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repo1").Return([]byte(`
+		{
+			"chart_repository_id": "00000000-0000-0000-0000-000000000001",
+			"name": "repo1",
+			"display_name": "Repo 1",
+			"url": "https://repo1.com",
+			"credentials": "ZW5jcnlwdGVk"
+		}
+		`), nil)
+		enc := &tests.EncrypterMock{}
+		enc.On("Decrypt", []byte("encrypted")).Return([]byte(`{"token": "token1"}`), nil)
+		m := NewManager(db, enc)
+
+		r, err := m.GetByName(context.Background(), "repo1")
+		require.NoError(t, err)
+		assert.Equal(t, "repo1", r.Name)
+		assert.Equal(t, &hub.ChartRepositoryCredentials{Token: "token1"}, r.Credentials)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+
+	t.Run("repository with credentials but encrypter not available", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repo1").Return([]byte(`
+		{
+			"name": "repo1",
+			"credentials": "ZW5jcnlwdGVk"
+		}
+		`), nil)
+		m := NewManager(db, nil)
+
+		r, err := m.GetByName(context.Background(), "repo1")
+		assert.Equal(t, ErrEncrypterNotAvailable, err)
+		assert.Nil(t, r)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error calling get_chart_repository_by_name", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		r, err := m.GetByName(context.Background(), "repo1")
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -160,7 +453,7 @@ func TestGetByName(t *testing.T) {
 	t.Run("invalid json data returned from database", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "repo1").Return([]byte("invalid json"), nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		r, err := m.GetByName(context.Background(), "repo1")
 		assert.Error(t, err)
@@ -169,6 +462,57 @@ func TestGetByName(t *testing.T) {
 	})
 }
 
+func TestGetMirrorIndexEntries(t *testing.T) {
+	dbQuery := "select get_mirror_index_entries($1::text)"
+	entriesJSON := []byte(`
+	[{
+		"chart_repository_name": "repo1",
+		"package_name": "pkg1",
+		"metadata": {"name": "pkg1", "version": "1.0.0"},
+		"digest": "digest"
+	}]
+	`)
+
+	t.Run("get mirror index entries of a repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.MatchedBy(func(name *string) bool {
+			return name != nil && *name == "repo1"
+		})).Return(entriesJSON, nil)
+		m := NewManager(db, nil)
+
+		entries, err := m.GetMirrorIndexEntries(context.Background(), "repo1")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "repo1", entries[0].ChartRepositoryName)
+		assert.Equal(t, "pkg1", entries[0].PackageName)
+		assert.JSONEq(t, `{"name": "pkg1", "version": "1.0.0"}`, string(entries[0].Metadata))
+		assert.Equal(t, "digest", entries[0].Digest)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("get mirror index entries of all repositories", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, (*string)(nil)).Return(entriesJSON, nil)
+		m := NewManager(db, nil)
+
+		entries, err := m.GetMirrorIndexEntries(context.Background(), "")
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		entries, err := m.GetMirrorIndexEntries(context.Background(), "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, entries)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetPackagesDigest(t *testing.T) {
 	dbQuery := "select get_chart_repository_packages_digest($1::uuid)"
 	db := &tests.DBMock{}
@@ -180,7 +524,7 @@ func TestGetPackagesDigest(t *testing.T) {
         "package2@0.0.9": "digest-package2-0.0.9"
     }
 	`), nil)
-	m := NewManager(db)
+	m := NewManager(db, nil)
 
 	pd, err := m.GetPackagesDigest(context.Background(), "00000000-0000-0000-0000-000000000001")
 	require.NoError(t, err)
@@ -197,7 +541,7 @@ func TestGetOwnedByOrgJSON(t *testing.T) {
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
 
 	t.Run("user id not found in ctx", func(t *testing.T) {
-		m := NewManager(nil)
+		m := NewManager(nil, nil)
 		assert.Panics(t, func() {
 			_, _ = m.GetOwnedByOrgJSON(context.Background(), "orgName")
 		})
@@ -206,7 +550,7 @@ func TestGetOwnedByOrgJSON(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "userID", "orgName").Return(nil, tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		dataJSON, err := m.GetOwnedByOrgJSON(ctx, "orgName")
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -217,7 +561,7 @@ func TestGetOwnedByOrgJSON(t *testing.T) {
 	t.Run("user chart repositories data returned successfully", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "userID", "orgName").Return([]byte("dataJSON"), nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		dataJSON, err := m.GetOwnedByOrgJSON(ctx, "orgName")
 		assert.NoError(t, err)
@@ -231,7 +575,7 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
 
 	t.Run("user id not found in ctx", func(t *testing.T) {
-		m := NewManager(nil)
+		m := NewManager(nil, nil)
 		assert.Panics(t, func() {
 			_, _ = m.GetOwnedByUserJSON(context.Background())
 		})
@@ -240,7 +584,7 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "userID").Return(nil, tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		dataJSON, err := m.GetOwnedByUserJSON(ctx)
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -251,7 +595,7 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 	t.Run("user chart repositories data returned successfully", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "userID").Return([]byte("dataJSON"), nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		dataJSON, err := m.GetOwnedByUserJSON(ctx)
 		assert.NoError(t, err)
@@ -260,17 +604,337 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 	})
 }
 
+func TestGetTrackingRequestJSON(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_request($1::uuid, $2::text, $3::uuid)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_, _ = m.GetTrackingRequestJSON(context.Background(), "repo1", "requestID")
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", "requestID").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.GetTrackingRequestJSON(ctx, "repo1", "requestID")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking request data returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", "requestID").Return([]byte("dataJSON"), nil)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.GetTrackingRequestJSON(ctx, "repo1", "requestID")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetTrackingRunErrorsJSON(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_run_errors($1::uuid, $2::text, $3::uuid)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_, _ = m.GetTrackingRunErrorsJSON(context.Background(), "repo1", "runID")
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", "runID").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.GetTrackingRunErrorsJSON(ctx, "repo1", "runID")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking run errors data returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", "runID").Return([]byte("dataJSON"), nil)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.GetTrackingRunErrorsJSON(ctx, "repo1", "runID")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetTrackingRunsJSON(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_runs($1::uuid, $2::text)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_, _ = m.GetTrackingRunsJSON(context.Background(), "repo1")
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.GetTrackingRunsJSON(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking runs data returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return([]byte("dataJSON"), nil)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.GetTrackingRunsJSON(ctx, "repo1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetWebhookSecret(t *testing.T) {
+	dbQuery := `
+	select coalesce(webhook_secret, '')
+	from chart_repository
+	where chart_repository_id = $1::uuid
+	and hosted = false`
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repoID").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		secret, err := m.GetWebhookSecret(context.Background(), "repoID")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Empty(t, secret)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("chart repository has no webhook secret", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repoID").Return("", nil)
+		m := NewManager(db, nil)
+
+		secret, err := m.GetWebhookSecret(context.Background(), "repoID")
+		assert.NoError(t, err)
+		assert.Empty(t, secret)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("encrypter not available", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repoID").Return("ZW5jcnlwdGVk", nil)
+		m := NewManager(db, nil)
+
+		secret, err := m.GetWebhookSecret(context.Background(), "repoID")
+		assert.Equal(t, ErrEncrypterNotAvailable, err)
+		assert.Empty(t, secret)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("webhook secret cannot be decrypted", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repoID").Return("ZW5jcnlwdGVk", nil)
+		enc := &tests.EncrypterMock{}
+		enc.On("Decrypt", []byte("encrypted")).Return(nil, tests.ErrFakeEncrypterFailure)
+		m := NewManager(db, enc)
+
+		secret, err := m.GetWebhookSecret(context.Background(), "repoID")
+		assert.Equal(t, tests.ErrFakeEncrypterFailure, err)
+		assert.Empty(t, secret)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+
+	t.Run("webhook secret returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repoID").Return("ZW5jcnlwdGVk", nil)
+		enc := &tests.EncrypterMock{}
+		enc.On("Decrypt", []byte("encrypted")).Return([]byte("secret"), nil)
+		m := NewManager(db, enc)
+
+		secret, err := m.GetWebhookSecret(context.Background(), "repoID")
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", secret)
+		db.AssertExpectations(t)
+		enc.AssertExpectations(t)
+	})
+}
+
+func TestHasAccess(t *testing.T) {
+	dbQuery := "select user_has_access_to_chart_repository($1::uuid, $2::text)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_, _ = m.HasAccess(context.Background(), "repo1")
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		hasAccess, err := m.HasAccess(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.False(t, hasAccess)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("access checked successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return(true, nil)
+		m := NewManager(db, nil)
+
+		hasAccess, err := m.HasAccess(ctx, "repo1")
+		assert.NoError(t, err)
+		assert.True(t, hasAccess)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestRegisterTrackingRun(t *testing.T) {
+	dbQuery := "select register_tracking_run($1::jsonb)"
+	run := &hub.TrackingRun{
+		ChartRepositoryID: "repoID",
+		StartedAt:         1,
+		FinishedAt:        2,
+		Processed:         1,
+		Failed:            1,
+		Errors: []*hub.TrackingError{
+			{
+				Kind:    hub.ChartLoadTrackingError,
+				Message: "error",
+			},
+		},
+	}
+
+	t.Run("database exec succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(nil)
+		m := NewManager(db, nil)
+
+		err := m.RegisterTrackingRun(context.Background(), run)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		err := m.RegisterTrackingRun(context.Background(), run)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestRequestTracking(t *testing.T) {
+	dbQuery := "select request_chart_repository_tracking($1::uuid, $2::text)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil, nil)
+		assert.Panics(t, func() {
+			_, _ = m.RequestTracking(context.Background(), "repo1")
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.RequestTracking(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("hosted chart repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return(nil, &pgconn.PgError{Code: hostedChartRepositoryErrCode})
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.RequestTracking(ctx, "repo1")
+		assert.Equal(t, ErrHostedChartRepository, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking requested successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return([]byte("dataJSON"), nil)
+		m := NewManager(db, nil)
+
+		dataJSON, err := m.RequestTracking(ctx, "repo1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestSetIndexCache(t *testing.T) {
+	dbQuery := "update chart_repository set index_cache = $2 where chart_repository_id = $1"
+	cache := &hub.ChartRepositoryIndexCache{
+		ETag:   "etag",
+		SHA256: "sha256",
+	}
+	cacheJSON := []byte(`{"etag":"etag","sha256":"sha256"}`)
+
+	t.Run("database update succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "repoID", cacheJSON).Return(nil)
+		m := NewManager(db, nil)
+
+		err := m.SetIndexCache(context.Background(), "repoID", cache)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "repoID", cacheJSON).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db, nil)
+
+		err := m.SetIndexCache(context.Background(), "repoID", cache)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestSetLastTrackingResults(t *testing.T) {
 	dbQuery := `
 	update chart_repository set
 		last_tracking_ts = current_timestamp,
-		last_tracking_errors = nullif($2, '')
+		last_tracking_errors = nullif($2, ''),
+		next_tracking_ts = current_timestamp + make_interval(
+			mins => tracking_interval,
+			secs => floor(random() * tracking_jitter * 60)
+		)
 	where chart_repository_id = $1`
 
 	t.Run("database update succeeded", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "repoID", "errors").Return(nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.SetLastTrackingResults(context.Background(), "repoID", "errors")
 		assert.NoError(t, err)
@@ -280,7 +944,7 @@ func TestSetLastTrackingResults(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "repoID", "errors").Return(tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.SetLastTrackingResults(context.Background(), "repoID", "errors")
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -299,7 +963,7 @@ func TestUpdate(t *testing.T) {
 	}
 
 	t.Run("user id not found in ctx", func(t *testing.T) {
-		m := NewManager(nil)
+		m := NewManager(nil, nil)
 		assert.Panics(t, func() {
 			_ = m.Update(context.Background(), r)
 		})
@@ -308,7 +972,7 @@ func TestUpdate(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "userID", mock.Anything).Return(tests.ErrFakeDatabaseFailure)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.Update(ctx, r)
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
@@ -318,10 +982,16 @@ func TestUpdate(t *testing.T) {
 	t.Run("update chart repository succeeded", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("Exec", dbQuery, "userID", mock.Anything).Return(nil)
-		m := NewManager(db)
+		m := NewManager(db, nil)
 
 		err := m.Update(ctx, r)
 		assert.NoError(t, err)
 		db.AssertExpectations(t)
 	})
 }
+
+func encrypterConfig() *viper.Viper {
+	cfg := viper.New()
+	cfg.Set("encryption.key", "00000000000000000000000000000000")
+	return cfg
+}
@@ -0,0 +1,99 @@
+package chartrepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// MirrorIndexEntry represents a chart version whose archive is available in
+// the hub's archive mirror.
+type MirrorIndexEntry struct {
+	ChartRepositoryName string          `json:"chart_repository_name"`
+	PackageName         string          `json:"package_name"`
+	Metadata            json.RawMessage `json:"metadata"`
+	Digest              string          `json:"digest"`
+}
+
+// mirrorIndex represents a Helm repository index file built from the charts
+// versions available in the mirror.
+type mirrorIndex struct {
+	APIVersion string                              `json:"apiVersion"`
+	Entries    map[string][]map[string]interface{} `json:"entries"`
+	Generated  time.Time                           `json:"generated"`
+}
+
+// MirrorArchivePath returns the path, relative to the chart repository mirror
+// location, of the archive of the chart version provided.
+func MirrorArchivePath(packageName, name, version string) string {
+	return path.Join("charts", packageName, version, fmt.Sprintf("%s-%s.tgz", name, version))
+}
+
+// BuildMirrorIndex builds a Helm repository index file (index.yaml) from the
+// mirror entries provided. Archives urls are relative to the index location.
+// When a combined index is requested, entries from several chart repositories
+// can be included, so charts are named after their repository to avoid
+// conflicts (repoName-chartName) and their urls are prefixed with the
+// repository name.
+func BuildMirrorIndex(entries []*MirrorIndexEntry, combined bool, generated time.Time) ([]byte, error) {
+	index := &mirrorIndex{
+		APIVersion: repo.APIVersionV1,
+		Entries:    make(map[string][]map[string]interface{}),
+		Generated:  generated,
+	}
+	for _, e := range entries {
+		var cv map[string]interface{}
+		if err := json.Unmarshal(e.Metadata, &cv); err != nil {
+			return nil, fmt.Errorf("invalid metadata in entry %s/%s: %w", e.ChartRepositoryName, e.PackageName, err)
+		}
+		name, _ := cv["name"].(string)
+		version, _ := cv["version"].(string)
+		if name == "" || version == "" {
+			return nil, fmt.Errorf("invalid metadata in entry %s/%s: name or version missing", e.ChartRepositoryName, e.PackageName)
+		}
+		u := MirrorArchivePath(e.PackageName, name, version)
+		key := name
+		if combined {
+			u = path.Join(e.ChartRepositoryName, u)
+			key = e.ChartRepositoryName + "-" + name
+		}
+		cv["urls"] = []string{u}
+		cv["digest"] = e.Digest
+		index.Entries[key] = append(index.Entries[key], cv)
+	}
+	for _, cvs := range index.Entries {
+		sortChartVersions(cvs)
+	}
+	return yaml.Marshal(index)
+}
+
+// sortChartVersions sorts the charts versions provided by version in
+// descending order, as Helm does. Versions that are not valid semver versions
+// are placed at the end.
+func sortChartVersions(cvs []map[string]interface{}) {
+	parse := func(cv map[string]interface{}) *semver.Version {
+		s, _ := cv["version"].(string)
+		v, err := semver.NewVersion(s)
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+	sort.SliceStable(cvs, func(i, j int) bool {
+		vi, vj := parse(cvs[i]), parse(cvs[j])
+		switch {
+		case vi == nil:
+			return false
+		case vj == nil:
+			return true
+		default:
+			return vi.GreaterThan(vj)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package chartrepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMirrorArchivePath(t *testing.T) {
+	assert.Equal(t, "charts/pkg1/1.0.0/Pkg1-1.0.0.tgz", MirrorArchivePath("pkg1", "Pkg1", "1.0.0"))
+}
+
+func TestBuildMirrorIndex(t *testing.T) {
+	generated := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*MirrorIndexEntry{
+		{
+			ChartRepositoryName: "repo1",
+			PackageName:         "pkg1",
+			Metadata:            []byte(`{"apiVersion": "v2", "name": "pkg1", "version": "1.0.0", "appVersion": "10.0"}`),
+			Digest:              "digest-pkg1-1.0.0",
+		},
+		{
+			ChartRepositoryName: "repo1",
+			PackageName:         "pkg1",
+			Metadata:            []byte(`{"apiVersion": "v2", "name": "pkg1", "version": "1.10.0"}`),
+			Digest:              "digest-pkg1-1.10.0",
+		},
+		{
+			ChartRepositoryName: "repo2",
+			PackageName:         "pkg1",
+			Metadata:            []byte(`{"apiVersion": "v2", "name": "pkg1", "version": "2.0.0"}`),
+			Digest:              "digest-repo2-pkg1-2.0.0",
+		},
+	}
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		testCases := []string{
+			`{`,
+			`{"name": "pkg1"}`,
+			`{"version": "1.0.0"}`,
+		}
+		for _, tc := range testCases {
+			data, err := BuildMirrorIndex([]*MirrorIndexEntry{{Metadata: []byte(tc)}}, false, generated)
+			assert.Error(t, err)
+			assert.Nil(t, data)
+		}
+	})
+
+	t.Run("repository index", func(t *testing.T) {
+		data, err := BuildMirrorIndex(entries[:2], false, generated)
+		require.NoError(t, err)
+		var index *repo.IndexFile
+		require.NoError(t, yaml.Unmarshal(data, &index))
+
+		assert.Equal(t, repo.APIVersionV1, index.APIVersion)
+		assert.Equal(t, generated, index.Generated)
+		require.Len(t, index.Entries, 1)
+		cvs := index.Entries["pkg1"]
+		require.Len(t, cvs, 2)
+		assert.Equal(t, "1.10.0", cvs[0].Version)
+		assert.Equal(t, []string{"charts/pkg1/1.10.0/pkg1-1.10.0.tgz"}, cvs[0].URLs)
+		assert.Equal(t, "digest-pkg1-1.10.0", cvs[0].Digest)
+		assert.Equal(t, "1.0.0", cvs[1].Version)
+		assert.Equal(t, "10.0", cvs[1].AppVersion)
+		assert.Equal(t, []string{"charts/pkg1/1.0.0/pkg1-1.0.0.tgz"}, cvs[1].URLs)
+	})
+
+	t.Run("combined index", func(t *testing.T) {
+		data, err := BuildMirrorIndex(entries, true, generated)
+		require.NoError(t, err)
+		var index *repo.IndexFile
+		require.NoError(t, yaml.Unmarshal(data, &index))
+
+		require.Len(t, index.Entries, 2)
+		require.Len(t, index.Entries["repo1-pkg1"], 2)
+		cvs := index.Entries["repo2-pkg1"]
+		require.Len(t, cvs, 1)
+		assert.Equal(t, "pkg1", cvs[0].Name)
+		assert.Equal(t, []string{"repo2/charts/pkg1/2.0.0/pkg1-2.0.0.tgz"}, cvs[0].URLs)
+		assert.Equal(t, "digest-repo2-pkg1-2.0.0", cvs[0].Digest)
+	})
+}
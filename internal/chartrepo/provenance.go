@@ -0,0 +1,95 @@
+package chartrepo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// ErrInvalidKeyring indicates that the keyring provided is not valid.
+	ErrInvalidKeyring = errors.New("invalid keyring")
+
+	// ErrInvalidProvenance indicates that the provenance file provided is not
+	// valid or it does not match the chart archive.
+	ErrInvalidProvenance = errors.New("invalid provenance file")
+)
+
+// ParseKeyring parses the ASCII armored keyring provided, which may contain
+// one or more public keys.
+func ParseKeyring(armoredKeyring string) (openpgp.EntityList, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKeyring))
+	if err != nil || len(keyring) == 0 {
+		return nil, ErrInvalidKeyring
+	}
+	return keyring, nil
+}
+
+// VerifyProvenance verifies the provenance file provided for the given chart
+// archive using the keyring provided, in the same way Helm does it: the
+// provenance file must be signed by one of the keys in the keyring and it must
+// contain the sha256 checksum of the chart archive under its file name. The
+// identity of the signer is returned when the verification succeeds.
+func VerifyProvenance(keyring openpgp.EntityList, archive []byte, archiveName string, prov []byte) (string, error) {
+	block, _ := clearsign.Decode(prov)
+	if block == nil {
+		return "", fmt.Errorf("%w: signature block not found", ErrInvalidProvenance)
+	}
+	signer, err := openpgp.CheckDetachedSignature(
+		keyring,
+		bytes.NewBuffer(block.Bytes),
+		block.ArmoredSignature.Body,
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidProvenance, err)
+	}
+
+	// The message block contains the chart metadata and the checksums of the
+	// files signed, separated by the yaml document end marker
+	parts := bytes.Split(block.Plaintext, []byte("\n...\n"))
+	if len(parts) < 2 {
+		return "", fmt.Errorf("%w: message block must have at least two parts", ErrInvalidProvenance)
+	}
+	var sums struct {
+		Files map[string]string `json:"files"`
+	}
+	if err := yaml.Unmarshal(parts[1], &sums); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidProvenance, err)
+	}
+	sum, ok := sums.Files[archiveName]
+	if !ok {
+		return "", fmt.Errorf("%w: checksum not found for file %s", ErrInvalidProvenance, archiveName)
+	}
+	if sum != fmt.Sprintf("sha256:%x", sha256.Sum256(archive)) {
+		return "", fmt.Errorf("%w: checksum does not match for file %s", ErrInvalidProvenance, archiveName)
+	}
+
+	return signerIdentity(signer), nil
+}
+
+// signerIdentity returns the identity of the entity provided: its primary
+// identity name when available (i.e. "Name <email>") or the fingerprint of its
+// primary key otherwise.
+func signerIdentity(e *openpgp.Entity) string {
+	names := make([]string, 0, len(e.Identities))
+	for name, identity := range e.Identities {
+		if identity.SelfSignature != nil &&
+			identity.SelfSignature.IsPrimaryId != nil &&
+			*identity.SelfSignature.IsPrimaryId {
+			return name
+		}
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return names[0]
+	}
+	return fmt.Sprintf("%X", e.PrimaryKey.Fingerprint)
+}
@@ -0,0 +1,118 @@
+package chartrepo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+func TestParseKeyring(t *testing.T) {
+	t.Run("invalid keyring", func(t *testing.T) {
+		keyring, err := ParseKeyring("invalid")
+		assert.True(t, errors.Is(err, ErrInvalidKeyring))
+		assert.Nil(t, keyring)
+	})
+
+	t.Run("valid keyring", func(t *testing.T) {
+		signer := newTestSigner(t)
+		keyring, err := ParseKeyring(armoredPublicKey(t, signer))
+		require.NoError(t, err)
+		assert.Len(t, keyring, 1)
+		assert.Equal(t, signer.PrimaryKey.KeyId, keyring[0].PrimaryKey.KeyId)
+	})
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	signer := newTestSigner(t)
+	keyring, err := ParseKeyring(armoredPublicKey(t, signer))
+	require.NoError(t, err)
+	archive := []byte("chart archive content")
+	archiveName := "chart-1.0.0.tgz"
+	archiveSum := fmt.Sprintf("sha256:%x", sha256.Sum256(archive))
+
+	t.Run("invalid provenance files", func(t *testing.T) {
+		testCases := []struct {
+			description string
+			signer      *openpgp.Entity
+			prov        []byte
+		}{
+			{
+				"signature block not found",
+				nil,
+				[]byte("invalid"),
+			},
+			{
+				"signed by a key not in the keyring",
+				newTestSigner(t),
+				[]byte("name: chart\n...\nfiles:\n  chart-1.0.0.tgz: " + archiveSum + "\n"),
+			},
+			{
+				"message block without checksums",
+				signer,
+				[]byte("name: chart\n"),
+			},
+			{
+				"checksum not found for the archive",
+				signer,
+				[]byte("name: chart\n...\nfiles:\n  other-1.0.0.tgz: " + archiveSum + "\n"),
+			},
+			{
+				"checksum does not match",
+				signer,
+				[]byte("name: chart\n...\nfiles:\n  chart-1.0.0.tgz: sha256:0123\n"),
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.description, func(t *testing.T) {
+				prov := tc.prov
+				if tc.signer != nil {
+					prov = clearSign(t, tc.signer, tc.prov)
+				}
+				identity, err := VerifyProvenance(keyring, archive, archiveName, prov)
+				assert.True(t, errors.Is(err, ErrInvalidProvenance))
+				assert.Empty(t, identity)
+			})
+		}
+	})
+
+	t.Run("valid provenance file", func(t *testing.T) {
+		prov := clearSign(t, signer, []byte("name: chart\n...\nfiles:\n  chart-1.0.0.tgz: "+archiveSum+"\n"))
+		identity, err := VerifyProvenance(keyring, archive, archiveName, prov)
+		assert.NoError(t, err)
+		assert.Equal(t, "signer1 <signer1@email.com>", identity)
+	})
+}
+
+func newTestSigner(t *testing.T) *openpgp.Entity {
+	e, err := openpgp.NewEntity("signer1", "", "signer1@email.com", nil)
+	require.NoError(t, err)
+	return e
+}
+
+func armoredPublicKey(t *testing.T, e *openpgp.Entity) string {
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, e.Serialize(w))
+	require.NoError(t, w.Close())
+	return b.String()
+}
+
+func clearSign(t *testing.T, e *openpgp.Entity, msg []byte) []byte {
+	var b bytes.Buffer
+	w, err := clearsign.Encode(&b, e.PrivateKey, nil)
+	require.NoError(t, err)
+	_, err = w.Write(msg)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
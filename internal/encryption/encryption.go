@@ -0,0 +1,85 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// minKeyLength represents the minimum length of the encryption key.
+const minKeyLength = 32
+
+var (
+	// ErrInvalidCiphertext indicates that the ciphertext provided could not be
+	// decrypted.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+	// ErrInvalidKey indicates that the encryption key provided is not valid.
+	ErrInvalidKey = errors.New("invalid encryption key: it must be at least 32 characters long")
+)
+
+// Encrypter is in charge of encrypting and decrypting sensitive data before
+// it is stored in the database, using AES-256-GCM.
+type Encrypter struct {
+	aead cipher.AEAD
+}
+
+// NewEncrypter creates a new Encrypter instance using the key provided in the
+// configuration. When no key has been provided nil is returned. An error is
+// returned if the key provided is not valid.
+func NewEncrypter(cfg *viper.Viper) (*Encrypter, error) {
+	key := cfg.GetString("encryption.key")
+	if key == "" {
+		return nil, nil
+	}
+	if len(key) < minKeyLength {
+		return nil, ErrInvalidKey
+	}
+	return newEncrypter(key)
+}
+
+// newEncrypter creates a new Encrypter instance using the key provided. The
+// key can have any length, a 256 bits key is derived from it.
+func newEncrypter(key string) (*Encrypter, error) {
+	derivedKey := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(derivedKey[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Encrypter{
+		aead: aead,
+	}, nil
+}
+
+// Encrypt encrypts the plaintext provided. The nonce used is prepended to the
+// ciphertext returned.
+func (e *Encrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts the ciphertext provided, which must have been generated by
+// Encrypt.
+func (e *Encrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := e.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := e.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,71 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEncrypter(t *testing.T) {
+	t.Run("key not provided", func(t *testing.T) {
+		e, err := NewEncrypter(viper.New())
+		assert.NoError(t, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("invalid key provided", func(t *testing.T) {
+		cfg := viper.New()
+		cfg.Set("encryption.key", "key")
+		e, err := NewEncrypter(cfg)
+		assert.Equal(t, ErrInvalidKey, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("key provided", func(t *testing.T) {
+		cfg := viper.New()
+		cfg.Set("encryption.key", "00000000000000000000000000000000")
+		e, err := NewEncrypter(cfg)
+		assert.NoError(t, err)
+		assert.NotNil(t, e)
+	})
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	e, err := newEncrypter("key")
+	require.NoError(t, err)
+
+	t.Run("encrypted data can be decrypted", func(t *testing.T) {
+		ciphertext, err := e.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(ciphertext), "secret")
+
+		plaintext, err := e.Decrypt(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), plaintext)
+	})
+
+	t.Run("same plaintext produces different ciphertexts", func(t *testing.T) {
+		ciphertext1, err := e.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		ciphertext2, err := e.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		assert.NotEqual(t, ciphertext1, ciphertext2)
+	})
+
+	t.Run("data encrypted with a different key cannot be decrypted", func(t *testing.T) {
+		e2, err := newEncrypter("key2")
+		require.NoError(t, err)
+		ciphertext, err := e2.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = e.Decrypt(ciphertext)
+		assert.Equal(t, ErrInvalidCiphertext, err)
+	})
+
+	t.Run("invalid ciphertext", func(t *testing.T) {
+		_, err := e.Decrypt([]byte("x"))
+		assert.Equal(t, ErrInvalidCiphertext, err)
+	})
+}
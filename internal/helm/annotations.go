@@ -0,0 +1,192 @@
+package helm
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"sigs.k8s.io/yaml"
+)
+
+// Artifact Hub specific annotations that can be used in the Chart.yaml file.
+const (
+	ChangesAnnotation                 = "artifacthub.io/changes"
+	ContainsSecurityUpdatesAnnotation = "artifacthub.io/containsSecurityUpdates"
+	CRDsAnnotation                    = "artifacthub.io/crds"
+	LicenseAnnotation                 = "artifacthub.io/license"
+	LinksAnnotation                   = "artifacthub.io/links"
+	OperatorAnnotation                = "artifacthub.io/operator"
+)
+
+// Annotations represents the information provided by a chart using the
+// Artifact Hub specific annotations.
+type Annotations struct {
+	Changes                 []string
+	ContainsSecurityUpdates bool
+	CRDs                    []*CRD
+	License                 string
+	Links                   []*hub.Link
+	Operator                bool
+}
+
+// CRD represents a custom resource definition provided by a chart, as
+// described in the crds annotation.
+type CRD struct {
+	Kind        string `json:"kind"`
+	Version     string `json:"version"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ParseAnnotations parses and validates the Artifact Hub specific annotations
+// found in the annotations provided. An error is returned for each of the
+// annotations that is not valid, which are ignored. The valid ones are
+// returned even if some errors were found.
+func ParseAnnotations(annotations map[string]string) (*Annotations, []error) {
+	a := &Annotations{}
+	var errs []error
+	invalid := func(annotation string, err error) {
+		errs = append(errs, fmt.Errorf("invalid %s annotation: %w", annotation, err))
+	}
+
+	// Changes
+	if v, ok := annotations[ChangesAnnotation]; ok {
+		var changes []string
+		if err := yaml.Unmarshal([]byte(v), &changes); err != nil {
+			invalid(ChangesAnnotation, err)
+		} else if err := validateNotEmpty(changes, "change"); err != nil {
+			invalid(ChangesAnnotation, err)
+		} else {
+			a.Changes = changes
+		}
+	}
+
+	// Contains security updates
+	if v, ok := annotations[ContainsSecurityUpdatesAnnotation]; ok {
+		containsSecurityUpdates, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			invalid(ContainsSecurityUpdatesAnnotation, fmt.Errorf("boolean expected, got %q", v))
+		} else {
+			a.ContainsSecurityUpdates = containsSecurityUpdates
+		}
+	}
+
+	// CRDs
+	if v, ok := annotations[CRDsAnnotation]; ok {
+		var crds []*CRD
+		if err := yaml.Unmarshal([]byte(v), &crds); err != nil {
+			invalid(CRDsAnnotation, err)
+		} else if err := validateCRDs(crds); err != nil {
+			invalid(CRDsAnnotation, err)
+		} else {
+			a.CRDs = crds
+		}
+	}
+
+	// License
+	if v, ok := annotations[LicenseAnnotation]; ok {
+		license := strings.TrimSpace(v)
+		if license == "" {
+			invalid(LicenseAnnotation, fmt.Errorf("license not provided"))
+		} else {
+			a.License = license
+		}
+	}
+
+	// Links
+	if v, ok := annotations[LinksAnnotation]; ok {
+		var links []*hub.Link
+		if err := yaml.Unmarshal([]byte(v), &links); err != nil {
+			invalid(LinksAnnotation, err)
+		} else if err := validateLinks(links); err != nil {
+			invalid(LinksAnnotation, err)
+		} else {
+			a.Links = links
+		}
+	}
+
+	// Operator
+	if v, ok := annotations[OperatorAnnotation]; ok {
+		operator, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			invalid(OperatorAnnotation, fmt.Errorf("boolean expected, got %q", v))
+		} else {
+			a.Operator = operator
+		}
+	}
+
+	return a, errs
+}
+
+// Data returns the information provided in the annotations, except the links,
+// in a format suitable to be stored in the package's data. Nil is returned if
+// no information is available.
+func (a *Annotations) Data() map[string]interface{} {
+	data := make(map[string]interface{})
+	if len(a.Changes) > 0 {
+		data["changes"] = a.Changes
+	}
+	if a.ContainsSecurityUpdates {
+		data["contains_security_updates"] = true
+	}
+	if len(a.CRDs) > 0 {
+		data["crds"] = a.CRDs
+	}
+	if a.License != "" {
+		data["license"] = a.License
+	}
+	if a.Operator {
+		data["operator"] = true
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// validateNotEmpty checks that none of the entries provided is empty.
+func validateNotEmpty(entries []string, name string) error {
+	for _, e := range entries {
+		if strings.TrimSpace(e) == "" {
+			return fmt.Errorf("empty %s found", name)
+		}
+	}
+	return nil
+}
+
+// validateCRDs checks that the kind, version and name of all the crds
+// provided have been set.
+func validateCRDs(crds []*CRD) error {
+	for _, crd := range crds {
+		if crd == nil || crd.Kind == "" || crd.Version == "" || crd.Name == "" {
+			return fmt.Errorf("crd kind, version and name must be provided")
+		}
+	}
+	return nil
+}
+
+// validateLinks checks that all the links provided have a name and a valid
+// http or https url.
+func validateLinks(links []*hub.Link) error {
+	for _, link := range links {
+		if link == nil || link.Name == "" {
+			return fmt.Errorf("link name must be provided")
+		}
+		if !isValidURL(link.URL) {
+			return fmt.Errorf("invalid url: %s", link.URL)
+		}
+	}
+	return nil
+}
+
+// isValidURL checks if the url provided is an absolute http or https url.
+func isValidURL(u string) bool {
+	tmp, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	return (tmp.Scheme == "http" || tmp.Scheme == "https") && tmp.Host != ""
+}
@@ -0,0 +1,95 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	t.Run("no annotations provided", func(t *testing.T) {
+		a, errs := ParseAnnotations(nil)
+		assert.Empty(t, errs)
+		assert.Equal(t, &Annotations{}, a)
+		assert.Nil(t, a.Data())
+	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		testCases := []struct {
+			annotation string
+			value      string
+		}{
+			{ChangesAnnotation, "invalid"},
+			{ChangesAnnotation, "- ''"},
+			{ContainsSecurityUpdatesAnnotation, "invalid"},
+			{CRDsAnnotation, "invalid"},
+			{CRDsAnnotation, "- kind: MyKind"},
+			{LicenseAnnotation, " "},
+			{LinksAnnotation, "invalid"},
+			{LinksAnnotation, "- url: https://link1.url"},
+			{LinksAnnotation, "- name: link1\n  url: invalid"},
+			{LinksAnnotation, "- name: link1\n  url: ftp://link1.url"},
+			{OperatorAnnotation, "invalid"},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.annotation+": "+tc.value, func(t *testing.T) {
+				a, errs := ParseAnnotations(map[string]string{tc.annotation: tc.value})
+				assert.Len(t, errs, 1)
+				assert.Contains(t, errs[0].Error(), tc.annotation)
+				assert.Nil(t, a.Data())
+				assert.Nil(t, a.Links)
+			})
+		}
+	})
+
+	t.Run("valid annotations are kept when others are invalid", func(t *testing.T) {
+		a, errs := ParseAnnotations(map[string]string{
+			LicenseAnnotation:  "Apache-2.0",
+			OperatorAnnotation: "invalid",
+		})
+		assert.Len(t, errs, 1)
+		assert.Equal(t, map[string]interface{}{"license": "Apache-2.0"}, a.Data())
+	})
+
+	t.Run("valid annotations", func(t *testing.T) {
+		a, errs := ParseAnnotations(map[string]string{
+			ChangesAnnotation: `
+- Added feature 1
+- Fixed bug 1
+`,
+			ContainsSecurityUpdatesAnnotation: "true",
+			CRDsAnnotation: `
+- kind: MyKind
+  version: v1
+  name: mykinds.example.com
+  displayName: My Kind
+  description: Some description
+`,
+			LicenseAnnotation: "Apache-2.0",
+			LinksAnnotation: `
+- name: link1
+  url: https://link1.url
+`,
+			OperatorAnnotation: "true",
+		})
+		assert.Empty(t, errs)
+		assert.Equal(t, []*hub.Link{{Name: "link1", URL: "https://link1.url"}}, a.Links)
+		assert.Equal(t, map[string]interface{}{
+			"changes":                   []string{"Added feature 1", "Fixed bug 1"},
+			"contains_security_updates": true,
+			"crds": []*CRD{
+				{
+					Kind:        "MyKind",
+					Version:     "v1",
+					Name:        "mykinds.example.com",
+					DisplayName: "My Kind",
+					Description: "Some description",
+				},
+			},
+			"license":  "Apache-2.0",
+			"operator": true,
+		}, a.Data())
+	})
+}
@@ -0,0 +1,164 @@
+package helm
+
+import (
+	"path"
+	"sort"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// apiDeprecation represents a Kubernetes API version of a given kind that has
+// been deprecated and, eventually, removed.
+type apiDeprecation struct {
+	apiVersion   string
+	kind         string
+	deprecatedIn string
+	removedIn    string
+	replacement  string
+}
+
+// apiDeprecations represents the table of Kubernetes API versions deprecated
+// that are checked in the charts rendered manifests.
+var apiDeprecations = []*apiDeprecation{
+	// Removed in 1.16
+	{"extensions/v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.10", "1.16", "policy/v1beta1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+
+	// Removed in 1.22
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.19", "1.22", "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.19", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.19", "1.22", "coordination.k8s.io/v1"},
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1alpha1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1alpha1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1alpha1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1alpha1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.13", "1.22", "storage.k8s.io/v1"},
+
+	// Removed in 1.25
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.19", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.20", "1.25", "node.k8s.io/v1"},
+
+	// Removed in 1.26
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"},
+}
+
+// GetDeprecatedAPIs returns the deprecated Kubernetes API versions used in
+// the rendered templates provided, sorted by the version in which they were
+// (or will be) removed. Nil is returned when no deprecated API versions are
+// found.
+func GetDeprecatedAPIs(manifests map[string]string) []*hub.DeprecatedAPI {
+	found := make(map[*apiDeprecation]struct{})
+	for name, content := range manifests {
+		ext := path.Ext(name)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		for _, doc := range releaseutil.SplitManifests(content) {
+			var obj struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+			}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				continue
+			}
+			for _, d := range apiDeprecations {
+				if d.apiVersion == obj.APIVersion && d.kind == obj.Kind {
+					found[d] = struct{}{}
+				}
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	deprecatedAPIs := make([]*hub.DeprecatedAPI, 0, len(found))
+	for d := range found {
+		deprecatedAPIs = append(deprecatedAPIs, &hub.DeprecatedAPI{
+			APIVersion:   d.apiVersion,
+			Kind:         d.kind,
+			DeprecatedIn: d.deprecatedIn,
+			RemovedIn:    d.removedIn,
+			Replacement:  d.replacement,
+		})
+	}
+	sort.Slice(deprecatedAPIs, func(i, j int) bool {
+		vi, _ := EncodeKubeVersion(deprecatedAPIs[i].RemovedIn)
+		vj, _ := EncodeKubeVersion(deprecatedAPIs[j].RemovedIn)
+		if vi != vj {
+			return vi < vj
+		}
+		if deprecatedAPIs[i].Kind != deprecatedAPIs[j].Kind {
+			return deprecatedAPIs[i].Kind < deprecatedAPIs[j].Kind
+		}
+		return deprecatedAPIs[i].APIVersion < deprecatedAPIs[j].APIVersion
+	})
+	return deprecatedAPIs
+}
+
+// LimitKubeVersionRanges limits the Kubernetes versions ranges provided to the
+// versions in which none of the deprecated API versions given have been
+// removed yet. Nil ranges mean that all versions are compatible.
+func LimitKubeVersionRanges(ranges []*hub.KubeVersionRange, deprecatedAPIs []*hub.DeprecatedAPI) []*hub.KubeVersionRange {
+	// Find the first version in which some of the API versions were removed
+	var removedIn int
+	for _, d := range deprecatedAPIs {
+		v, err := EncodeKubeVersion(d.RemovedIn)
+		if err != nil {
+			continue
+		}
+		if removedIn == 0 || v < removedIn {
+			removedIn = v
+		}
+	}
+	if removedIn == 0 {
+		return ranges
+	}
+
+	// Cut the ranges at the last version before the removal
+	limit := removedIn - 1
+	if ranges == nil {
+		return []*hub.KubeVersionRange{{Max: intPtr(limit)}}
+	}
+	limited := make([]*hub.KubeVersionRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Min != nil && *r.Min > limit {
+			continue
+		}
+		max := r.Max
+		if max == nil || *max > limit {
+			max = intPtr(limit)
+		}
+		limited = append(limited, &hub.KubeVersionRange{Min: r.Min, Max: max})
+	}
+	return limited
+}
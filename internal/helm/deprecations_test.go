@@ -0,0 +1,120 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDeprecatedAPIs(t *testing.T) {
+	t.Run("no deprecated api versions found", func(t *testing.T) {
+		manifests := map[string]string{
+			"chart/templates/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+`,
+		}
+		assert.Nil(t, GetDeprecatedAPIs(manifests))
+		assert.Nil(t, GetDeprecatedAPIs(nil))
+	})
+
+	t.Run("deprecated api versions found", func(t *testing.T) {
+		manifests := map[string]string{
+			"chart/templates/deployment.yaml": `
+apiVersion: extensions/v1beta1
+kind: Deployment
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+`,
+			"chart/templates/ingress.yaml": `
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+`,
+			"chart/templates/cronjob.yaml": `
+apiVersion: batch/v1beta1
+kind: CronJob
+`,
+			"chart/templates/NOTES.txt": `
+apiVersion: apps/v1beta1
+kind: Deployment
+`,
+		}
+		assert.Equal(t, []*hub.DeprecatedAPI{
+			{
+				APIVersion:   "extensions/v1beta1",
+				Kind:         "Deployment",
+				DeprecatedIn: "1.9",
+				RemovedIn:    "1.16",
+				Replacement:  "apps/v1",
+			},
+			{
+				APIVersion:   "networking.k8s.io/v1beta1",
+				Kind:         "Ingress",
+				DeprecatedIn: "1.19",
+				RemovedIn:    "1.22",
+				Replacement:  "networking.k8s.io/v1",
+			},
+			{
+				APIVersion:   "batch/v1beta1",
+				Kind:         "CronJob",
+				DeprecatedIn: "1.21",
+				RemovedIn:    "1.25",
+				Replacement:  "batch/v1",
+			},
+		}, GetDeprecatedAPIs(manifests))
+	})
+}
+
+func TestLimitKubeVersionRanges(t *testing.T) {
+	deprecatedAPIs := []*hub.DeprecatedAPI{
+		{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", RemovedIn: "1.22"},
+		{APIVersion: "batch/v1beta1", Kind: "CronJob", RemovedIn: "1.25"},
+	}
+
+	testCases := []struct {
+		description    string
+		ranges         []*hub.KubeVersionRange
+		deprecatedAPIs []*hub.DeprecatedAPI
+		expected       []*hub.KubeVersionRange
+	}{
+		{
+			"no deprecated api versions",
+			[]*hub.KubeVersionRange{{Min: intPtr(1016000)}},
+			nil,
+			[]*hub.KubeVersionRange{{Min: intPtr(1016000)}},
+		},
+		{
+			"no ranges",
+			nil,
+			deprecatedAPIs,
+			[]*hub.KubeVersionRange{{Max: intPtr(1021999)}},
+		},
+		{
+			"ranges limited",
+			[]*hub.KubeVersionRange{
+				{Max: intPtr(1014049)},
+				{Min: intPtr(1016000), Max: intPtr(1023049)},
+				{Min: intPtr(1024000)},
+			},
+			deprecatedAPIs,
+			[]*hub.KubeVersionRange{
+				{Max: intPtr(1014049)},
+				{Min: intPtr(1016000), Max: intPtr(1021999)},
+			},
+		},
+		{
+			"no compatible versions left",
+			[]*hub.KubeVersionRange{{Min: intPtr(1023000)}},
+			deprecatedAPIs,
+			[]*hub.KubeVersionRange{},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, LimitKubeVersionRanges(tc.ranges, tc.deprecatedAPIs))
+		})
+	}
+}
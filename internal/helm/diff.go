@@ -0,0 +1,173 @@
+package helm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"sigs.k8s.io/yaml"
+)
+
+// Kinds of changes found when comparing two chart versions.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// Diff represents the differences between two versions of a chart.
+type Diff struct {
+	Metadata  []*KeyChange  `json:"metadata"`
+	Values    []*KeyChange  `json:"values"`
+	Templates []*FileChange `json:"templates"`
+}
+
+// KeyChange represents a change in a key of a yaml document, like the chart's
+// metadata or default values. Keys of nested maps are joined using dots.
+type KeyChange struct {
+	Key    string      `json:"key"`
+	Change string      `json:"change"`
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// FileChange represents a change in a file of a chart. Changed files include a
+// unified diff of their content.
+type FileChange struct {
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// DiffArchives returns the differences found in the metadata (Chart.yaml),
+// default values (values.yaml) and templates between the files of the two
+// charts archives provided.
+func DiffArchives(from, to []*loader.BufferedFile) (*Diff, error) {
+	fromFiles, toFiles := filesByName(from), filesByName(to)
+
+	metadata, err := diffYAML(fromFiles["Chart.yaml"], toFiles["Chart.yaml"])
+	if err != nil {
+		return nil, fmt.Errorf("error comparing Chart.yaml: %w", err)
+	}
+	values, err := diffYAML(fromFiles["values.yaml"], toFiles["values.yaml"])
+	if err != nil {
+		return nil, fmt.Errorf("error comparing values.yaml: %w", err)
+	}
+
+	return &Diff{
+		Metadata:  metadata,
+		Values:    values,
+		Templates: diffFiles(fromFiles, toFiles, "templates/"),
+	}, nil
+}
+
+// filesByName returns the files provided indexed by their name.
+func filesByName(files []*loader.BufferedFile) map[string][]byte {
+	m := make(map[string][]byte, len(files))
+	for _, f := range files {
+		m[f.Name] = f.Data
+	}
+	return m
+}
+
+// diffYAML returns the keys added, removed or changed between the two yaml
+// documents provided, sorted by key.
+func diffYAML(from, to []byte) ([]*KeyChange, error) {
+	var fromDoc, toDoc map[string]interface{}
+	if err := yaml.Unmarshal(from, &fromDoc); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(to, &toDoc); err != nil {
+		return nil, err
+	}
+	fromKeys, toKeys := make(map[string]interface{}), make(map[string]interface{})
+	flatten("", fromDoc, fromKeys)
+	flatten("", toDoc, toKeys)
+
+	changes := make([]*KeyChange, 0)
+	for k, fromValue := range fromKeys {
+		toValue, ok := toKeys[k]
+		switch {
+		case !ok:
+			changes = append(changes, &KeyChange{Key: k, Change: DiffRemoved, From: fromValue})
+		case !reflect.DeepEqual(fromValue, toValue):
+			changes = append(changes, &KeyChange{Key: k, Change: DiffChanged, From: fromValue, To: toValue})
+		}
+	}
+	for k, toValue := range toKeys {
+		if _, ok := fromKeys[k]; !ok {
+			changes = append(changes, &KeyChange{Key: k, Change: DiffAdded, To: toValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// flatten adds the leaf values of the map provided to the keys map given,
+// using as key the path to each of them joined by dots. Lists are considered
+// leaf values. Empty maps are kept as they are.
+func flatten(prefix string, m map[string]interface{}, keys map[string]interface{}) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flatten(key, nested, keys)
+			continue
+		}
+		keys[key] = v
+	}
+}
+
+// diffFiles returns the files whose name starts with the prefix provided
+// that were added, removed or changed between the two sets of files given,
+// sorted by name.
+func diffFiles(from, to map[string][]byte, prefix string) []*FileChange {
+	changes := make([]*FileChange, 0)
+	for name, fromData := range from {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		toData, ok := to[name]
+		switch {
+		case !ok:
+			changes = append(changes, &FileChange{Name: name, Change: DiffRemoved})
+		case string(fromData) != string(toData):
+			diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        splitLines(fromData),
+				B:        splitLines(toData),
+				FromFile: name,
+				ToFile:   name,
+				Context:  3,
+			})
+			changes = append(changes, &FileChange{Name: name, Change: DiffChanged, Diff: diff})
+		}
+	}
+	for name := range to {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, ok := from[name]; !ok {
+			changes = append(changes, &FileChange{Name: name, Change: DiffAdded})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// splitLines splits the data provided in lines, keeping the line endings.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
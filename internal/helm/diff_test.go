@@ -0,0 +1,87 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestDiffArchives(t *testing.T) {
+	from := []*loader.BufferedFile{
+		{Name: "Chart.yaml", Data: []byte("name: chart\nversion: 1.0.0\ndescription: desc\n")},
+		{Name: "values.yaml", Data: []byte(`
+image:
+  repository: repo/app
+  tag: 1.0.0
+replicas: 1
+ports: [80]
+`)},
+		{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\nreplicas: 1\n")},
+		{Name: "templates/service.yaml", Data: []byte("kind: Service\n")},
+		{Name: "README.md", Data: []byte("readme 1")},
+	}
+	to := []*loader.BufferedFile{
+		{Name: "Chart.yaml", Data: []byte("name: chart\nversion: 1.1.0\nkubeVersion: '>=1.16.0'\n")},
+		{Name: "values.yaml", Data: []byte(`
+image:
+  repository: repo/app
+  tag: 1.1.0
+  pullPolicy: Always
+ports: [80]
+`)},
+		{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\nreplicas: 2\n")},
+		{Name: "templates/ingress.yaml", Data: []byte("kind: Ingress\n")},
+		{Name: "README.md", Data: []byte("readme 2")},
+	}
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		invalid := []*loader.BufferedFile{
+			{Name: "values.yaml", Data: []byte("{")},
+		}
+		d, err := DiffArchives(from, invalid)
+		assert.Error(t, err)
+		assert.Nil(t, d)
+	})
+
+	t.Run("differences found", func(t *testing.T) {
+		d, err := DiffArchives(from, to)
+		require.NoError(t, err)
+		assert.Equal(t, []*KeyChange{
+			{Key: "description", Change: DiffRemoved, From: "desc"},
+			{Key: "kubeVersion", Change: DiffAdded, To: ">=1.16.0"},
+			{Key: "version", Change: DiffChanged, From: "1.0.0", To: "1.1.0"},
+		}, d.Metadata)
+		assert.Equal(t, []*KeyChange{
+			{Key: "image.pullPolicy", Change: DiffAdded, To: "Always"},
+			{Key: "image.tag", Change: DiffChanged, From: "1.0.0", To: "1.1.0"},
+			{Key: "replicas", Change: DiffRemoved, From: float64(1)},
+		}, d.Values)
+		assert.Equal(t, []*FileChange{
+			{
+				Name:   "templates/deployment.yaml",
+				Change: DiffChanged,
+				Diff: `--- templates/deployment.yaml
++++ templates/deployment.yaml
+@@ -1,2 +1,2 @@
+ kind: Deployment
+-replicas: 1
++replicas: 2
+`,
+			},
+			{Name: "templates/ingress.yaml", Change: DiffAdded},
+			{Name: "templates/service.yaml", Change: DiffRemoved},
+		}, d.Templates)
+	})
+
+	t.Run("no differences", func(t *testing.T) {
+		d, err := DiffArchives(from, from)
+		require.NoError(t, err)
+		assert.Equal(t, &Diff{
+			Metadata:  []*KeyChange{},
+			Values:    []*KeyChange{},
+			Templates: []*FileChange{},
+		}, d)
+	})
+}
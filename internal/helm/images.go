@@ -0,0 +1,114 @@
+package helm
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// ImagesAnnotation represents the annotation that charts can use to list the
+// containers images they deploy.
+const ImagesAnnotation = "artifacthub.io/images"
+
+// containersFields represents the fields of a pod spec that contain lists of
+// containers.
+var containersFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// GetImagesFromAnnotation returns the containers images listed in the images
+// annotation of the chart metadata provided, if any. The annotation is
+// expected to contain a yaml list of objects with a name and an image.
+func GetImagesFromAnnotation(md *chart.Metadata) ([]string, error) {
+	v, ok := md.Annotations[ImagesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var entries []struct {
+		Name  string `json:"name"`
+		Image string `json:"image"`
+	}
+	if err := yaml.Unmarshal([]byte(v), &entries); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", ImagesAnnotation, err)
+	}
+	images := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if strings.TrimSpace(e.Image) == "" {
+			return nil, fmt.Errorf("invalid %s annotation: image not provided", ImagesAnnotation)
+		}
+		images = append(images, strings.TrimSpace(e.Image))
+	}
+	return images, nil
+}
+
+// GetImagesFromManifests returns the containers images referenced in the
+// workloads defined in the rendered templates provided.
+func GetImagesFromManifests(manifests map[string]string) []string {
+	var images []string
+	for name, content := range manifests {
+		ext := path.Ext(name)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		for _, doc := range releaseutil.SplitManifests(content) {
+			var obj interface{}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				continue
+			}
+			images = append(images, getContainersImages(obj)...)
+		}
+	}
+	return images
+}
+
+// getContainersImages walks the object provided looking for lists of
+// containers, returning the images they reference.
+func getContainersImages(obj interface{}) []string {
+	var images []string
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for _, field := range containersFields {
+			containers, ok := v[field].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if image, ok := container["image"].(string); ok && strings.TrimSpace(image) != "" {
+					images = append(images, strings.TrimSpace(image))
+				}
+			}
+		}
+		for _, e := range v {
+			images = append(images, getContainersImages(e)...)
+		}
+	case []interface{}:
+		for _, e := range v {
+			images = append(images, getContainersImages(e)...)
+		}
+	}
+	return images
+}
+
+// DedupImages returns a sorted list of the images provided without duplicates.
+func DedupImages(images []string) []string {
+	if len(images) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(images))
+	for _, image := range images {
+		set[image] = struct{}{}
+	}
+	deduped := make([]string, 0, len(set))
+	for image := range set {
+		deduped = append(deduped, image)
+	}
+	sort.Strings(deduped)
+	return deduped
+}
@@ -0,0 +1,96 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestGetImagesFromAnnotation(t *testing.T) {
+	t.Run("annotation not provided", func(t *testing.T) {
+		images, err := GetImagesFromAnnotation(&chart.Metadata{})
+		assert.NoError(t, err)
+		assert.Nil(t, images)
+	})
+
+	t.Run("invalid annotation", func(t *testing.T) {
+		testCases := []string{
+			"invalid",
+			"- name: image1",
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc, func(t *testing.T) {
+				md := &chart.Metadata{
+					Annotations: map[string]string{ImagesAnnotation: tc},
+				}
+				images, err := GetImagesFromAnnotation(md)
+				assert.Error(t, err)
+				assert.Nil(t, images)
+			})
+		}
+	})
+
+	t.Run("valid annotation", func(t *testing.T) {
+		md := &chart.Metadata{
+			Annotations: map[string]string{
+				ImagesAnnotation: `
+- name: app
+  image: repo/app:1.0.0
+- name: sidecar
+  image: repo/sidecar:2.0.0
+`,
+			},
+		}
+		images, err := GetImagesFromAnnotation(md)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"repo/app:1.0.0", "repo/sidecar:2.0.0"}, images)
+	})
+}
+
+func TestGetImagesFromManifests(t *testing.T) {
+	manifests := map[string]string{
+		"chart/templates/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: init
+          image: repo/init:1.0.0
+      containers:
+        - name: app
+          image: repo/app:1.0.0
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: job
+              image: repo/job:1.0.0
+`,
+		"chart/templates/configmap.yaml": `
+apiVersion: v1
+kind: ConfigMap
+data:
+  image: repo/ignored:1.0.0
+`,
+		"chart/templates/NOTES.txt": `
+containers:
+  - image: repo/ignored:1.0.0
+`,
+	}
+	images := DedupImages(GetImagesFromManifests(manifests))
+	assert.Equal(t, []string{"repo/app:1.0.0", "repo/init:1.0.0", "repo/job:1.0.0"}, images)
+}
+
+func TestDedupImages(t *testing.T) {
+	assert.Nil(t, DedupImages(nil))
+	assert.Equal(t, []string{"a", "b"}, DedupImages([]string{"b", "a", "b"}))
+}
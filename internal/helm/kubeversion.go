@@ -0,0 +1,86 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/hub"
+)
+
+// Limits of the Kubernetes versions checked against a kubeVersion constraint
+// to build the ranges of versions it is compatible with. Only 1.x versions
+// are considered, and versions beyond the limits are assumed to behave like
+// the last one checked.
+const (
+	kubeVersionMajor    = 1
+	kubeVersionMaxMinor = 99
+	kubeVersionMaxPatch = 49
+)
+
+// ErrInvalidKubeVersion indicates that the Kubernetes version provided is not
+// valid.
+var ErrInvalidKubeVersion = errors.New("invalid kubernetes version")
+
+// EncodeKubeVersion encodes the Kubernetes version provided as an integer
+// (major*1000000 + minor*1000 + patch), suitable to be compared against the
+// ranges returned by GetKubeVersionRanges. Any pre-release or metadata
+// information (i.e. v1.18.3-gke.1) is ignored.
+func EncodeKubeVersion(version string) (int, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidKubeVersion, version)
+	}
+	if v.Major() > 999 || v.Minor() > 999 || v.Patch() > 999 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidKubeVersion, version)
+	}
+	return encodeKubeVersion(int(v.Major()), int(v.Minor()), int(v.Patch())), nil
+}
+
+// GetKubeVersionRanges returns the ranges of Kubernetes versions compatible
+// with the kubeVersion constraint provided. An empty list is returned when
+// the constraint is not compatible with any of the versions checked.
+func GetKubeVersionRanges(constraint string) ([]*hub.KubeVersionRange, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeVersion constraint %q: %w", constraint, err)
+	}
+
+	ranges := make([]*hub.KubeVersionRange, 0)
+	var current *hub.KubeVersionRange
+	first := true
+	for minor := 0; minor <= kubeVersionMaxMinor; minor++ {
+		for patch := 0; patch <= kubeVersionMaxPatch; patch++ {
+			encodedVersion := encodeKubeVersion(kubeVersionMajor, minor, patch)
+			v := semver.MustParse(fmt.Sprintf("%d.%d.%d", kubeVersionMajor, minor, patch))
+			if c.Check(v) {
+				if current == nil {
+					current = &hub.KubeVersionRange{}
+					if !first {
+						current.Min = intPtr(encodedVersion)
+					}
+					ranges = append(ranges, current)
+				}
+				current.Max = intPtr(encodedVersion)
+			} else {
+				current = nil
+			}
+			first = false
+		}
+	}
+	if current != nil {
+		current.Max = nil
+	}
+
+	return ranges, nil
+}
+
+// encodeKubeVersion encodes the version provided as an integer.
+func encodeKubeVersion(major, minor, patch int) int {
+	return major*1000000 + minor*1000 + patch
+}
+
+// intPtr returns a pointer to the int provided.
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,91 @@
+package helm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeKubeVersion(t *testing.T) {
+	t.Run("invalid version", func(t *testing.T) {
+		testCases := []string{
+			"invalid",
+			"1.1000.0",
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc, func(t *testing.T) {
+				_, err := EncodeKubeVersion(tc)
+				assert.True(t, errors.Is(err, ErrInvalidKubeVersion))
+			})
+		}
+	})
+
+	t.Run("valid version", func(t *testing.T) {
+		testCases := []struct {
+			version  string
+			expected int
+		}{
+			{"1.18.3", 1018003},
+			{"v1.18.3-gke.1", 1018003},
+			{"1.16", 1016000},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.version, func(t *testing.T) {
+				encodedVersion, err := EncodeKubeVersion(tc.version)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, encodedVersion)
+			})
+		}
+	})
+}
+
+func TestGetKubeVersionRanges(t *testing.T) {
+	t.Run("invalid constraint", func(t *testing.T) {
+		ranges, err := GetKubeVersionRanges("invalid")
+		assert.Error(t, err)
+		assert.Nil(t, ranges)
+	})
+
+	t.Run("valid constraint", func(t *testing.T) {
+		testCases := []struct {
+			constraint string
+			expected   []*hub.KubeVersionRange
+		}{
+			{
+				">=1.16.0-0",
+				[]*hub.KubeVersionRange{
+					{Min: intPtr(1016000), Max: nil},
+				},
+			},
+			{
+				"<1.16.0",
+				[]*hub.KubeVersionRange{
+					{Min: nil, Max: intPtr(1015049)},
+				},
+			},
+			{
+				">=1.14.0 <1.16.0 || ~1.18.2",
+				[]*hub.KubeVersionRange{
+					{Min: intPtr(1014000), Max: intPtr(1015049)},
+					{Min: intPtr(1018002), Max: intPtr(1018049)},
+				},
+			},
+			{
+				">=2.0.0",
+				[]*hub.KubeVersionRange{},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.constraint, func(t *testing.T) {
+				ranges, err := GetKubeVersionRanges(tc.constraint)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, ranges)
+			})
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package helm
+
+import (
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// Names used for the links built from the chart metadata fields.
+const (
+	homeLinkName   = "Homepage"
+	sourceLinkName = "Source"
+)
+
+// BuildLinks builds the package links from the links provided (usually those
+// coming from the links annotation) and the home, sources and maintainers urls
+// available in the chart metadata. Links with urls that are not absolute http
+// or https urls are discarded, as well as those pointing to an url already
+// seen. Nil is returned when no links are available.
+func BuildLinks(md *chart.Metadata, links []*hub.Link) []*hub.Link {
+	var result []*hub.Link
+	seen := make(map[string]struct{})
+	add := func(name, u string) {
+		u = strings.TrimSpace(u)
+		if !isValidURL(u) {
+			return
+		}
+		key := strings.TrimSuffix(u, "/")
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		result = append(result, &hub.Link{Name: strings.TrimSpace(name), URL: u})
+	}
+
+	for _, link := range links {
+		if link != nil {
+			add(link.Name, link.URL)
+		}
+	}
+	if md != nil {
+		add(homeLinkName, md.Home)
+		for _, source := range md.Sources {
+			add(sourceLinkName, source)
+		}
+		for _, maintainer := range md.Maintainers {
+			if maintainer != nil && maintainer.Name != "" {
+				add(maintainer.Name, maintainer.URL)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,46 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestBuildLinks(t *testing.T) {
+	t.Run("no links available", func(t *testing.T) {
+		assert.Nil(t, BuildLinks(nil, nil))
+		assert.Nil(t, BuildLinks(&chart.Metadata{}, nil))
+	})
+
+	t.Run("links built, validated and deduplicated", func(t *testing.T) {
+		md := &chart.Metadata{
+			Home: "https://home.url",
+			Sources: []string{
+				"https://github.com/org/repo",
+				"https://github.com/org/repo/",
+				"invalid",
+				" https://source2.url ",
+			},
+			Maintainers: []*chart.Maintainer{
+				{Name: "maintainer1", URL: "https://maintainer1.url"},
+				{Name: "maintainer2"},
+				{Name: "maintainer3", URL: "https://home.url/"},
+				{URL: "https://maintainer4.url"},
+			},
+		}
+		links := []*hub.Link{
+			{Name: "link1", URL: "https://link1.url"},
+			{Name: "link2", URL: "ftp://link2.url"},
+			{Name: "Source", URL: "https://github.com/org/repo"},
+		}
+		assert.Equal(t, []*hub.Link{
+			{Name: "link1", URL: "https://link1.url"},
+			{Name: "Source", URL: "https://github.com/org/repo"},
+			{Name: "Homepage", URL: "https://home.url"},
+			{Name: "Source", URL: "https://source2.url"},
+			{Name: "maintainer1", URL: "https://maintainer1.url"},
+		}, BuildLinks(md, links))
+	})
+}
@@ -0,0 +1,91 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// lintNamespace represents the namespace used when linting charts.
+const lintNamespace = "default"
+
+// Points subtracted from the maximum quality score for each lint message
+// found, depending on its severity.
+const (
+	maxQualityScore    = 100
+	lintErrorPenalty   = 25
+	lintWarningPenalty = 10
+	lintInfoPenalty    = 2
+)
+
+// lintSeverities maps the Helm linter severities to the names used in the
+// lint reports.
+var lintSeverities = map[int]string{
+	support.UnknownSev: hub.LintUnknown,
+	support.InfoSev:    hub.LintInfo,
+	support.WarningSev: hub.LintWarning,
+	support.ErrorSev:   hub.LintError,
+}
+
+// lintChart runs the Helm linter on the chart provided, using its default
+// values, and returns the messages reported. As the linter works on a chart
+// directory, the chart is written to a temporary directory that is removed
+// once the lint process is done. The linter renders the chart templates, so
+// charts must only be linted in render processes (see Renderer).
+func lintChart(c *chart.Chart) ([]*hub.LintMessage, error) {
+	if c.Metadata == nil || c.Name() == "" || filepath.Base(c.Name()) != c.Name() || c.Name() == ".." {
+		return nil, fmt.Errorf("invalid chart name: %s", c.Name())
+	}
+	tmpDir, err := ioutil.TempDir("", "artifacthub-lint")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := chartutil.SaveDir(c, tmpDir); err != nil {
+		return nil, err
+	}
+
+	chartDir := filepath.Join(tmpDir, c.Name())
+	linter := lint.All(chartDir, nil, lintNamespace, false)
+	messages := make([]*hub.LintMessage, 0, len(linter.Messages))
+	for _, m := range linter.Messages {
+		var text string
+		if m.Err != nil {
+			text = strings.ReplaceAll(m.Err.Error(), chartDir+string(filepath.Separator), "")
+		}
+		messages = append(messages, &hub.LintMessage{
+			Severity: lintSeverities[m.Severity],
+			Path:     m.Path,
+			Message:  text,
+		})
+	}
+	return messages, nil
+}
+
+// QualityScore returns a quality score (0-100) for a chart based on the lint
+// messages provided.
+func QualityScore(messages []*hub.LintMessage) int {
+	score := maxQualityScore
+	for _, m := range messages {
+		switch m.Severity {
+		case hub.LintError:
+			score -= lintErrorPenalty
+		case hub.LintWarning:
+			score -= lintWarningPenalty
+		case hub.LintInfo:
+			score -= lintInfoPenalty
+		}
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
@@ -0,0 +1,101 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestLintChart(t *testing.T) {
+	t.Run("invalid chart name", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "../chart"},
+		}
+		messages, err := lintChart(c)
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+	})
+
+	t.Run("chart linted", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "chart",
+				Version:    "1.0.0",
+			},
+			Raw: []*chart.File{
+				{
+					Name: "values.yaml",
+					Data: []byte("key: value\n"),
+				},
+			},
+			Templates: []*chart.File{
+				{
+					Name: "templates/configmap.yaml",
+					Data: []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+data:
+  key: {{ .Values.key }
+`),
+				},
+			},
+		}
+		messages, err := lintChart(c)
+		require.NoError(t, err)
+		severities := make([]string, 0, len(messages))
+		for _, m := range messages {
+			severities = append(severities, m.Severity)
+			assert.NotContains(t, m.Message, "artifacthub-lint")
+		}
+		assert.Equal(t, []string{hub.LintInfo, hub.LintError}, severities)
+		assert.Equal(t, "Chart.yaml", messages[0].Path)
+		assert.Equal(t, "templates/", messages[1].Path)
+	})
+}
+
+func TestQualityScore(t *testing.T) {
+	testCases := []struct {
+		description string
+		messages    []*hub.LintMessage
+		expected    int
+	}{
+		{
+			"no messages",
+			nil,
+			100,
+		},
+		{
+			"some messages",
+			[]*hub.LintMessage{
+				{Severity: hub.LintError},
+				{Severity: hub.LintWarning},
+				{Severity: hub.LintInfo},
+				{Severity: hub.LintUnknown},
+			},
+			63,
+		},
+		{
+			"score never below zero",
+			[]*hub.LintMessage{
+				{Severity: hub.LintError},
+				{Severity: hub.LintError},
+				{Severity: hub.LintError},
+				{Severity: hub.LintError},
+				{Severity: hub.LintError},
+			},
+			0,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, QualityScore(tc.messages))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// NewPackage builds a hub package from the chart provided, extracting all the
+// information available in it (readme, values, dependencies, lint report,
+// containers images, etc). The url provided is used as the package content
+// url. Charts come from untrusted sources, so they are linted and their
+// templates rendered using the renderer given, which limits the time and
+// resources used. When the renderer is busy, ErrRendererBusy is returned
+// right away. Problems found processing the chart that do not prevent the
+// package from being registered are returned as tracking errors.
+func NewPackage(c *chart.Chart, u string, r *Renderer) (*hub.Package, []*hub.TrackingError, error) {
+	md := c.Metadata
+	var errs []*hub.TrackingError
+	appendError := func(kind hub.TrackingErrorKind, err error) {
+		errs = append(errs, &hub.TrackingError{
+			Kind:         kind,
+			ChartName:    md.Name,
+			ChartVersion: md.Version,
+			URL:          u,
+			Message:      err.Error(),
+		})
+	}
+
+	p := &hub.Package{
+		Kind:        hub.Chart,
+		Name:        md.Name,
+		Description: md.Description,
+		HomeURL:     md.Home,
+		Keywords:    md.Keywords,
+		Deprecated:  md.Deprecated,
+		Version:     md.Version,
+		AppVersion:  md.AppVersion,
+		ContentURL:  u,
+	}
+
+	readme := getFile(c, "README.md")
+	if readme != nil {
+		p.Readme = string(readme.Data)
+	}
+	values := getRawFile(c, "values.yaml")
+	if values != nil {
+		p.DefaultValues = string(values.Data)
+		valuesDocs, err := GetValuesDocs(values.Data)
+		if err != nil {
+			appendError(hub.InvalidChartTrackingError, fmt.Errorf(
+				"error parsing values documentation of chart %s version %s: %w", md.Name, md.Version, err,
+			))
+		} else {
+			p.ValuesDocs = valuesDocs
+		}
+	}
+	if len(c.Schema) > 0 {
+		if json.Valid(c.Schema) {
+			p.ValuesSchema = c.Schema
+		} else {
+			appendError(hub.InvalidChartTrackingError, fmt.Errorf(
+				"invalid values schema in chart %s version %s", md.Name, md.Version,
+			))
+		}
+	}
+	var dependencies []*hub.Dependency
+	for _, dep := range md.Dependencies {
+		dependencies = append(dependencies, &hub.Dependency{
+			Name:       dep.Name,
+			Version:    dep.Version,
+			Repository: dep.Repository,
+			Condition:  dep.Condition,
+			Alias:      dep.Alias,
+		})
+	}
+	if len(dependencies) > 0 {
+		p.Dependencies = dependencies
+	}
+	var maintainers []*hub.Maintainer
+	for _, entry := range md.Maintainers {
+		if entry.Email != "" {
+			maintainers = append(maintainers, &hub.Maintainer{
+				Name:  entry.Name,
+				Email: entry.Email,
+			})
+		}
+	}
+	if len(maintainers) > 0 {
+		p.Maintainers = maintainers
+	}
+
+	// Artifact Hub annotations
+	annotations, annotationsErrs := ParseAnnotations(md.Annotations)
+	for _, err := range annotationsErrs {
+		appendError(hub.InvalidChartTrackingError, fmt.Errorf(
+			"error parsing annotations of chart %s version %s: %w", md.Name, md.Version, err,
+		))
+	}
+	p.Links = BuildLinks(md, annotations.Links)
+	p.Data = annotations.Data()
+
+	// Lint chart and render its templates using the default values, both in
+	// a render process (charts whose templates cannot be rendered are
+	// registered anyway)
+	a, err := r.analyzeChart(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.lintErr == nil {
+		for _, m := range a.lintMessages {
+			if m.Severity == hub.LintError {
+				appendError(hub.LintTrackingError, fmt.Errorf(
+					"lint error in chart %s version %s: %s: %s", md.Name, md.Version, m.Path, m.Message,
+				))
+			}
+		}
+		score := QualityScore(a.lintMessages)
+		p.LintReport = a.lintMessages
+		p.QualityScore = &score
+	}
+	manifests := make(map[string]string)
+	if a.renderErr != nil {
+		appendError(hub.InvalidChartTrackingError, fmt.Errorf(
+			"error rendering templates of chart %s version %s: %w", md.Name, md.Version, a.renderErr,
+		))
+	} else {
+		for _, m := range a.chart.Manifests {
+			manifests[m.Name] = m.Content
+		}
+	}
+
+	// Containers images
+	images, err := GetImagesFromAnnotation(md)
+	if err != nil {
+		appendError(hub.InvalidChartTrackingError, fmt.Errorf(
+			"error getting containers images of chart %s version %s: %w", md.Name, md.Version, err,
+		))
+	}
+	images = append(images, GetImagesFromManifests(manifests)...)
+	p.ContainerImages = DedupImages(images)
+
+	// Kubernetes versions compatibility, limited by the deprecated API
+	// versions used in the chart templates
+	if md.KubeVersion != "" {
+		ranges, err := GetKubeVersionRanges(md.KubeVersion)
+		if err != nil {
+			appendError(hub.InvalidChartTrackingError, fmt.Errorf(
+				"error parsing kubeVersion of chart %s version %s: %w", md.Name, md.Version, err,
+			))
+		} else {
+			p.KubeVersion = md.KubeVersion
+			p.KubeVersionRanges = ranges
+		}
+	}
+	p.DeprecatedAPIs = GetDeprecatedAPIs(manifests)
+	p.KubeVersionRanges = LimitKubeVersionRanges(p.KubeVersionRanges, p.DeprecatedAPIs)
+
+	return p, errs, nil
+}
+
+// getFile returns the file requested from the provided chart.
+func getFile(c *chart.Chart, name string) *chart.File {
+	for _, file := range c.Files {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
+
+// getRawFile returns the file requested from the provided chart's raw files.
+// Files like values.yaml are not available in the chart's files list, but
+// their original content can be found in the raw files.
+func getRawFile(c *chart.Chart, name string) *chart.File {
+	for _, file := range c.Raw {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestNewPackage(t *testing.T) {
+	r := NewRenderer(1, time.Minute, 1024*1024)
+	u := "https://repo1.com/chart-1.0.0.tgz"
+	chartYAML := []byte("apiVersion: v2\nname: chart\nversion: 1.0.0\n")
+	podTemplate := []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  containers:
+    - name: app
+      image: {{ .Values.image }}
+`)
+
+	t.Run("package built from chart", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion:  chart.APIVersionV2,
+				Name:        "chart",
+				Version:     "1.0.0",
+				AppVersion:  "2.0.0",
+				Description: "description",
+				Home:        "https://chart.home",
+				Keywords:    []string{"kw1"},
+				KubeVersion: ">=1.16.0-0",
+				Dependencies: []*chart.Dependency{
+					{Name: "dep1", Version: "1.0.0", Repository: "https://dep1.repo"},
+				},
+				Maintainers: []*chart.Maintainer{
+					{Name: "name1", Email: "name1@email.com"},
+					{Name: "name2"},
+				},
+			},
+			Files: []*chart.File{
+				{Name: "README.md", Data: []byte("readme")},
+			},
+			Raw: []*chart.File{
+				{Name: "Chart.yaml", Data: chartYAML},
+				{Name: "values.yaml", Data: []byte("# -- Image repository\nimage: repo/app\n")},
+				{Name: "templates/pod.yaml", Data: podTemplate},
+			},
+			Schema: []byte(`{"type": "object"}`),
+			Templates: []*chart.File{
+				{
+					Name: "templates/pod.yaml",
+					Data: podTemplate,
+				},
+			},
+			Values: map[string]interface{}{
+				"image": "repo/app",
+			},
+		}
+		p, errs, err := NewPackage(c, u, r)
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+		assert.Equal(t, hub.Chart, p.Kind)
+		assert.Equal(t, "chart", p.Name)
+		assert.Equal(t, "1.0.0", p.Version)
+		assert.Equal(t, "2.0.0", p.AppVersion)
+		assert.Equal(t, "description", p.Description)
+		assert.Equal(t, "https://chart.home", p.HomeURL)
+		assert.Equal(t, []string{"kw1"}, p.Keywords)
+		assert.Equal(t, u, p.ContentURL)
+		assert.Equal(t, "readme", p.Readme)
+		assert.Equal(t, "# -- Image repository\nimage: repo/app\n", p.DefaultValues)
+		require.Len(t, p.ValuesDocs, 1)
+		assert.Equal(t, "image", p.ValuesDocs[0].Key)
+		assert.Equal(t, []byte(`{"type": "object"}`), []byte(p.ValuesSchema))
+		assert.Equal(t, []*hub.Dependency{
+			{Name: "dep1", Version: "1.0.0", Repository: "https://dep1.repo"},
+		}, p.Dependencies)
+		assert.Equal(t, []*hub.Maintainer{{Name: "name1", Email: "name1@email.com"}}, p.Maintainers)
+		assert.Equal(t, []string{"repo/app"}, p.ContainerImages)
+		assert.Equal(t, ">=1.16.0-0", p.KubeVersion)
+		assert.NotEmpty(t, p.KubeVersionRanges)
+		assert.NotNil(t, p.QualityScore)
+	})
+
+	t.Run("invalid chart information reported as tracking errors", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion:  chart.APIVersionV2,
+				Name:        "chart",
+				Version:     "1.0.0",
+				KubeVersion: "invalid",
+			},
+			Raw: []*chart.File{
+				{Name: "Chart.yaml", Data: chartYAML},
+			},
+			Schema: []byte("{"),
+		}
+		p, errs, err := NewPackage(c, u, r)
+		require.NoError(t, err)
+		assert.Nil(t, p.ValuesSchema)
+		assert.Empty(t, p.KubeVersion)
+		require.Len(t, errs, 2)
+		for _, e := range errs {
+			assert.Equal(t, hub.InvalidChartTrackingError, e.Kind)
+			assert.Equal(t, "chart", e.ChartName)
+			assert.Equal(t, "1.0.0", e.ChartVersion)
+			assert.Equal(t, u, e.URL)
+		}
+		assert.Equal(t, "invalid values schema in chart chart version 1.0.0", errs[0].Message)
+	})
+
+	t.Run("templates rendering errors reported as tracking errors", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "chart",
+				Version:    "1.0.0",
+			},
+			Raw: []*chart.File{
+				{Name: "Chart.yaml", Data: chartYAML},
+				{Name: "templates/pod.yaml", Data: []byte(`{{ required "image is required" .Values.image }}`)},
+			},
+		}
+		p, errs, err := NewPackage(c, u, r)
+		require.NoError(t, err)
+		assert.Empty(t, p.ContainerImages)
+		require.Len(t, errs, 1)
+		assert.Equal(t, hub.InvalidChartTrackingError, errs[0].Kind)
+		assert.Contains(t, errs[0].Message, "error rendering templates of chart chart version 1.0.0")
+		assert.Contains(t, errs[0].Message, "image is required")
+	})
+	t.Run("renderer busy", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "chart",
+				Version:    "1.0.0",
+			},
+			Raw: []*chart.File{
+				{Name: "Chart.yaml", Data: chartYAML},
+			},
+		}
+		r := NewRenderer(1, time.Minute, 1024*1024)
+		r.sem <- struct{}{}
+		p, errs, err := NewPackage(c, u, r)
+		assert.Equal(t, ErrRendererBusy, err)
+		assert.Nil(t, p)
+		assert.Nil(t, errs)
+	})
+}
@@ -0,0 +1,396 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// notesFileName represents the name of the chart template used to render the
+// release notes.
+const notesFileName = "NOTES.txt"
+
+var (
+	// ErrRendererBusy indicates that the maximum number of concurrent renders
+	// has been reached.
+	ErrRendererBusy = errors.New("too many renders in progress")
+
+	// ErrRenderTimeout indicates that the chart templates rendering took
+	// longer than allowed.
+	ErrRenderTimeout = errors.New("chart templates rendering timed out")
+
+	// ErrRenderOutputTooLarge indicates that the manifests rendered exceed the
+	// maximum size allowed.
+	ErrRenderOutputTooLarge = errors.New("rendered manifests too large")
+)
+
+// releaseOptions represents the options of the fake release used when
+// rendering charts templates.
+var releaseOptions = chartutil.ReleaseOptions{
+	Name:      "release-name",
+	Namespace: "default",
+	Revision:  1,
+	IsInstall: true,
+}
+
+// RenderTemplates renders offline the templates of the chart provided using
+// the values given, which are merged with the chart's default values, as Helm
+// would do when installing it. The rendered templates are returned indexed by
+// their path. The chart provided may be modified, as the dependencies disabled
+// by the values are removed from it.
+func RenderTemplates(c *chart.Chart, values map[string]interface{}) (map[string]string, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	if err := chartutil.ProcessDependencies(c, values); err != nil {
+		return nil, err
+	}
+	renderValues, err := chartutil.ToRenderValues(c, values, releaseOptions, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, err
+	}
+	return engine.Render(c, renderValues)
+}
+
+// Manifest represents a chart template rendered.
+type Manifest struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// RenderedChart represents the output of rendering a chart, as Helm would
+// produce it when installing it.
+type RenderedChart struct {
+	Manifests []*Manifest `json:"manifests"`
+	Notes     string      `json:"notes"`
+}
+
+// Renderer renders charts templates offline, limiting the number of renders
+// that can run concurrently, the time each of them can take and the size of
+// the manifests produced. Each render runs in a separate process of the
+// current executable (see HandleRenderProcess), so that renders taking too
+// long can be actually stopped and the memory they can use is bounded.
+type Renderer struct {
+	sem           chan struct{}
+	timeout       time.Duration
+	maxOutputSize int
+}
+
+// NewRenderer creates a new Renderer instance.
+func NewRenderer(maxConcurrentRenders int, timeout time.Duration, maxOutputSize int) *Renderer {
+	return &Renderer{
+		sem:           make(chan struct{}, maxConcurrentRenders),
+		timeout:       timeout,
+		maxOutputSize: maxOutputSize,
+	}
+}
+
+// Render renders the templates of the chart archive files provided using the
+// values given (yaml or json). Empty manifests are omitted and the remaining
+// ones are returned sorted by name. When the maximum number of concurrent
+// renders has been reached, ErrRendererBusy is returned right away. Renders
+// that time out are killed, releasing their slot.
+func (r *Renderer) Render(files []*loader.BufferedFile, values []byte) (*RenderedChart, error) {
+	select {
+	case r.sem <- struct{}{}:
+	default:
+		return nil, ErrRendererBusy
+	}
+	defer func() { <-r.sem }()
+	out, err := r.run(&renderInput{
+		Files:         files,
+		Values:        values,
+		MaxOutputSize: r.maxOutputSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Chart, out.renderErr()
+}
+
+// chartAnalysis represents the result of linting a chart and rendering its
+// templates using its default values.
+type chartAnalysis struct {
+	lintMessages []*hub.LintMessage
+	lintErr      error
+	chart        *RenderedChart
+	renderErr    error
+}
+
+// analyzeChart lints the chart provided and renders its templates using its
+// default values, both in the same render process. As in Render, when the
+// maximum number of concurrent renders has been reached, ErrRendererBusy is
+// returned right away. The chart must have been loaded from its files, as
+// they are taken from the chart's raw files.
+func (r *Renderer) analyzeChart(c *chart.Chart) (*chartAnalysis, error) {
+	select {
+	case r.sem <- struct{}{}:
+	default:
+		return nil, ErrRendererBusy
+	}
+	defer func() { <-r.sem }()
+	files := make([]*loader.BufferedFile, 0, len(c.Raw))
+	for _, f := range c.Raw {
+		files = append(files, &loader.BufferedFile{Name: f.Name, Data: f.Data})
+	}
+	out, err := r.run(&renderInput{
+		Files:         files,
+		MaxOutputSize: r.maxOutputSize,
+		Lint:          true,
+	})
+	if err != nil {
+		return &chartAnalysis{lintErr: err, renderErr: err}, nil
+	}
+	a := &chartAnalysis{
+		lintMessages: out.LintMessages,
+		chart:        out.Chart,
+		renderErr:    out.renderErr(),
+	}
+	if out.LintErr != "" {
+		a.lintErr = errors.New(out.LintErr)
+	}
+	return a, nil
+}
+
+// run runs a new render process with the input provided, killing it if it
+// does not complete in time.
+func (r *Renderer) run(in *renderInput) (*renderOutput, error) {
+	if !renderProcessEnabled {
+		return nil, errRenderProcessNotEnabled
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error getting executable path: %w", err)
+	}
+	var input bytes.Buffer
+	if err := gob.NewEncoder(&input).Encode(in); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, executable)
+	cmd.Env = []string{renderProcessEnv + "=1"}
+	cmd.Stdin = &input
+	output := &limitedBuffer{limit: r.maxOutputSize + renderOutputOverhead}
+	cmd.Stdout = output
+	err = cmd.Run()
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, ErrRenderTimeout
+	case output.exceeded:
+		return nil, ErrRenderOutputTooLarge
+	case err != nil:
+		return nil, fmt.Errorf("error rendering chart templates: %w", err)
+	}
+
+	var out renderOutput
+	if err := gob.NewDecoder(&output.buf).Decode(&out); err != nil {
+		return nil, fmt.Errorf("error decoding rendered chart: %w", err)
+	}
+	return &out, nil
+}
+
+// renderProcessEnv represents the environment variable used to run the
+// current executable as a render process (see HandleRenderProcess).
+const renderProcessEnv = "HUB_HELM_RENDER_PROCESS"
+
+// renderProcessEnabled indicates whether the current executable can be run as
+// a render process, which is the case once HandleRenderProcess is called.
+var renderProcessEnabled bool
+
+// errRenderProcessNotEnabled indicates that renders cannot be run as the
+// current executable does not handle render processes.
+var errRenderProcessNotEnabled = errors.New("render process not enabled: HandleRenderProcess not called")
+
+// renderProcessMaxMemory represents the maximum amount of memory a render
+// process can use.
+const renderProcessMaxMemory = 1024 * 1024 * 1024
+
+// renderOutputOverhead represents the extra space allowed in the output of a
+// render process on top of the rendered manifests (names, encoding, etc).
+const renderOutputOverhead = 1024 * 1024
+
+// renderErrKind represents the kind of an error that occurred in a render
+// process.
+type renderErrKind int
+
+const (
+	noRenderError renderErrKind = iota
+	invalidValuesRenderError
+	outputTooLargeRenderError
+	otherRenderError
+)
+
+// renderInput represents the input of a render process. When Lint is set,
+// the chart is linted as well before rendering its templates.
+type renderInput struct {
+	Files         []*loader.BufferedFile
+	Values        []byte
+	MaxOutputSize int
+	Lint          bool
+}
+
+// renderOutput represents the output of a render process.
+type renderOutput struct {
+	Chart        *RenderedChart
+	Err          string
+	ErrKind      renderErrKind
+	LintMessages []*hub.LintMessage
+	LintErr      string
+}
+
+// renderErr returns the error that occurred rendering the chart templates in
+// the render process, if any.
+func (out *renderOutput) renderErr() error {
+	switch out.ErrKind {
+	case noRenderError:
+		return nil
+	case invalidValuesRenderError:
+		return fmt.Errorf("%w: %s", ErrInvalidValues, out.Err)
+	case outputTooLargeRenderError:
+		return ErrRenderOutputTooLarge
+	default:
+		return errors.New(out.Err)
+	}
+}
+
+// HandleRenderProcess must be called at the beginning of the main function of
+// the executables using renderers, as renders run in separate processes of
+// the current executable. When the current process has been started by a
+// renderer, it renders the chart received and exits. Otherwise, it enables
+// renderers to start render processes.
+func HandleRenderProcess() {
+	if os.Getenv(renderProcessEnv) != "" {
+		os.Exit(runRenderProcess(os.Stdin, os.Stdout))
+	}
+	renderProcessEnabled = true
+}
+
+// runRenderProcess renders (and lints, if requested) the chart read from the
+// reader provided, writing the result to the writer given. It returns the
+// process exit code.
+func runRenderProcess(r io.Reader, w io.Writer) int {
+	limitRenderProcessMemory(renderProcessMaxMemory)
+
+	var in renderInput
+	if err := gob.NewDecoder(r).Decode(&in); err != nil {
+		return 1
+	}
+	var out renderOutput
+	if in.Lint {
+		messages, err := lintFiles(in.Files)
+		if err != nil {
+			out.LintErr = err.Error()
+		}
+		out.LintMessages = messages
+	}
+	rc, err := renderFiles(in.Files, in.Values, in.MaxOutputSize)
+	switch {
+	case err == nil:
+		out.Chart = rc
+	case errors.Is(err, ErrInvalidValues):
+		out.Err, out.ErrKind = err.Error(), invalidValuesRenderError
+	case errors.Is(err, ErrRenderOutputTooLarge):
+		out.Err, out.ErrKind = err.Error(), outputTooLargeRenderError
+	default:
+		out.Err, out.ErrKind = err.Error(), otherRenderError
+	}
+	if err := gob.NewEncoder(w).Encode(&out); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// lintFiles lints the chart files provided.
+func lintFiles(files []*loader.BufferedFile) (messages []*hub.LintMessage, err error) {
+	c, err := loader.LoadFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("error loading chart: %w", err)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			messages, err = nil, fmt.Errorf("error linting chart: %v", rec)
+		}
+	}()
+	return lintChart(c)
+}
+
+// renderFiles renders the templates of the chart files provided using the
+// values given (yaml or json).
+func renderFiles(files []*loader.BufferedFile, values []byte, maxOutputSize int) (rc *RenderedChart, err error) {
+	vals, err := chartutil.ReadValues(values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValues, err)
+	}
+	c, err := loader.LoadFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("error loading chart: %w", err)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			rc, err = nil, fmt.Errorf("error rendering chart templates: %v", rec)
+		}
+	}()
+	templates, err := RenderTemplates(c, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	rc = &RenderedChart{
+		Manifests: make([]*Manifest, 0, len(templates)),
+	}
+	var size int
+	for name, content := range templates {
+		size += len(content)
+		if size > maxOutputSize {
+			return nil, ErrRenderOutputTooLarge
+		}
+		if path.Base(name) == notesFileName {
+			if name == path.Join(c.Name(), "templates", notesFileName) {
+				rc.Notes = content
+			}
+			continue
+		}
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		rc.Manifests = append(rc.Manifests, &Manifest{Name: name, Content: content})
+	}
+	sort.Slice(rc.Manifests, func(i, j int) bool {
+		return rc.Manifests[i].Name < rc.Manifests[j].Name
+	})
+	return rc, nil
+}
+
+// limitedBuffer is a buffer that fails writes once its content would exceed
+// the limit set.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+// Write implements the io.Writer interface.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		b.exceeded = true
+		return 0, ErrRenderOutputTooLarge
+	}
+	return b.buf.Write(p)
+}
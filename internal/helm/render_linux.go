@@ -0,0 +1,10 @@
+package helm
+
+import "syscall"
+
+// limitRenderProcessMemory limits the data segment of the current process to
+// the number of bytes provided, so that renders using too much memory fail
+// instead of exhausting the memory available in the host.
+func limitRenderProcessMemory(limit uint64) {
+	_ = syscall.Setrlimit(syscall.RLIMIT_DATA, &syscall.Rlimit{Cur: limit, Max: limit})
+}
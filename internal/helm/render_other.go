@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package helm
+
+// limitRenderProcessMemory is a no-op on platforms other than Linux, where
+// the memory used by render processes is not limited.
+func limitRenderProcessMemory(limit uint64) {}
@@ -0,0 +1,250 @@
+package helm
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestMain(m *testing.M) {
+	HandleRenderProcess()
+	os.Exit(m.Run())
+}
+
+func TestRenderTemplates(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "chart",
+			Version:    "1.0.0",
+		},
+		Templates: []*chart.File{
+			{
+				Name: "templates/deployment.yaml",
+				Data: []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: {{ .Values.image.repository }}:{{ .Values.image.tag }}
+`),
+			},
+		},
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "repo/app",
+				"tag":        "1.0.0",
+			},
+		},
+	}
+
+	t.Run("default values", func(t *testing.T) {
+		manifests, err := RenderTemplates(c, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"repo/app:1.0.0"}, GetImagesFromManifests(manifests))
+	})
+
+	t.Run("custom values", func(t *testing.T) {
+		values := map[string]interface{}{
+			"image": map[string]interface{}{
+				"tag": "2.0.0",
+			},
+		}
+		manifests, err := RenderTemplates(c, values)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"repo/app:2.0.0"}, GetImagesFromManifests(manifests))
+	})
+}
+
+func TestRenderer(t *testing.T) {
+	files := []*loader.BufferedFile{
+		{
+			Name: "Chart.yaml",
+			Data: []byte("apiVersion: v2\nname: chart\nversion: 1.0.0\n"),
+		},
+		{
+			Name: "values.yaml",
+			Data: []byte("replicaCount: 1\nsize: 1\n"),
+		},
+		{
+			Name: "templates/_helpers.tpl",
+			Data: []byte(`{{- define "chart.name" -}}{{ .Chart.Name }}{{- end -}}`),
+		},
+		{
+			Name: "templates/deployment.yaml",
+			Data: []byte(`name: {{ include "chart.name" . }}
+replicas: {{ .Values.replicaCount }}
+{{ range until (int .Values.size) }}x{{ end }}`),
+		},
+		{
+			Name: "templates/empty.yaml",
+			Data: []byte(`{{- if .Values.fail }}{{ fail "rendering failed" }}{{- end }}`),
+		},
+		{
+			Name: "templates/NOTES.txt",
+			Data: []byte("Release {{ .Release.Name }} installed"),
+		},
+	}
+
+	t.Run("render process not enabled", func(t *testing.T) {
+		renderProcessEnabled = false
+		defer func() { renderProcessEnabled = true }()
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files, nil)
+		assert.Equal(t, errRenderProcessNotEnabled, err)
+		assert.Nil(t, rc)
+		assert.Len(t, r.sem, 0)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files, []byte("key: [value"))
+		assert.True(t, errors.Is(err, ErrInvalidValues))
+		assert.Nil(t, rc)
+	})
+
+	t.Run("invalid chart", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files[1:], nil)
+		assert.Error(t, err)
+		assert.Nil(t, rc)
+	})
+
+	t.Run("renderer busy", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		r.sem <- struct{}{}
+		rc, err := r.Render(files, nil)
+		assert.Equal(t, ErrRendererBusy, err)
+		assert.Nil(t, rc)
+	})
+
+	t.Run("render timeout", func(t *testing.T) {
+		r := NewRenderer(1, time.Millisecond, 10*1024*1024)
+		rc, err := r.Render(files, []byte("size: 5000000"))
+		assert.Equal(t, ErrRenderTimeout, err)
+		assert.Nil(t, rc)
+	})
+
+	t.Run("render output too large", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files, []byte("size: 2000"))
+		assert.Equal(t, ErrRenderOutputTooLarge, err)
+		assert.Nil(t, rc)
+	})
+
+	t.Run("render template error", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files, []byte("fail: true"))
+		assert.Contains(t, err.Error(), "rendering failed")
+		assert.Nil(t, rc)
+	})
+
+	t.Run("chart rendered", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files, []byte("replicaCount: 3\nsize: 2"))
+		require.NoError(t, err)
+		assert.Equal(t, &RenderedChart{
+			Manifests: []*Manifest{
+				{
+					Name:    "chart/templates/deployment.yaml",
+					Content: "name: chart\nreplicas: 3\nxx",
+				},
+			},
+			Notes: "Release release-name installed",
+		}, rc)
+		assert.Len(t, r.sem, 0)
+	})
+
+	t.Run("render killed and slot released when timing out", func(t *testing.T) {
+		r := NewRenderer(1, 100*time.Millisecond, 10*1024*1024)
+		start := time.Now()
+		_, err := r.Render(files, []byte("size: 5000000"))
+		assert.Equal(t, ErrRenderTimeout, err)
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+		assert.Len(t, r.sem, 0)
+	})
+
+	t.Run("render using too much memory fails", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("render processes memory is only limited on linux")
+		}
+		r := NewRenderer(1, time.Minute, 1024)
+		rc, err := r.Render(files, []byte("size: 1000000000"))
+		assert.Error(t, err)
+		assert.Nil(t, rc)
+		assert.Len(t, r.sem, 0)
+	})
+}
+
+func TestRendererAnalyzeChart(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "chart",
+			Version:    "1.0.0",
+		},
+		Raw: []*chart.File{
+			{
+				Name: "Chart.yaml",
+				Data: []byte("apiVersion: v2\nname: chart\nversion: 1.0.0\n"),
+			},
+			{
+				Name: "values.yaml",
+				Data: []byte("size: 1\n"),
+			},
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("data: {{ range until (int .Values.size) }}x{{ end }}"),
+			},
+		},
+	}
+
+	t.Run("renderer busy", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		r.sem <- struct{}{}
+		a, err := r.analyzeChart(c)
+		assert.Equal(t, ErrRendererBusy, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("chart linted and rendered", func(t *testing.T) {
+		r := NewRenderer(1, time.Minute, 1024)
+		a, err := r.analyzeChart(c)
+		require.NoError(t, err)
+		require.NoError(t, a.lintErr)
+		require.NoError(t, a.renderErr)
+		assert.NotEmpty(t, a.lintMessages)
+		assert.Equal(t, &RenderedChart{
+			Manifests: []*Manifest{
+				{
+					Name:    "chart/templates/configmap.yaml",
+					Content: "data: x",
+				},
+			},
+		}, a.chart)
+		assert.Len(t, r.sem, 0)
+	})
+
+	t.Run("lint and render timeout", func(t *testing.T) {
+		r := NewRenderer(1, time.Millisecond, 1024)
+		a, err := r.analyzeChart(c)
+		require.NoError(t, err)
+		assert.Equal(t, ErrRenderTimeout, a.lintErr)
+		assert.Equal(t, ErrRenderTimeout, a.renderErr)
+		assert.Nil(t, a.lintMessages)
+		assert.Nil(t, a.chart)
+		assert.Len(t, r.sem, 0)
+	})
+}
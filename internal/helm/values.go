@@ -0,0 +1,94 @@
+package helm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+var (
+	// ErrInvalidValues indicates that the values provided could not be parsed.
+	ErrInvalidValues = errors.New("invalid values")
+
+	// ErrInvalidValuesSchema indicates that the values json schema could not
+	// be used to validate the values provided.
+	ErrInvalidValuesSchema = errors.New("invalid values schema")
+)
+
+// ValuesValidation represents the result of validating some values against a
+// chart's values json schema.
+type ValuesValidation struct {
+	Valid  bool           `json:"valid"`
+	Errors []*ValuesError `json:"errors"`
+}
+
+// ValuesError represents an error found when validating some values. The path
+// is the dotted path of the key the error refers to (empty for the root).
+type ValuesError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidateValues merges the values provided (yaml or json) with the chart's
+// default values, the same way Helm does, and validates the result against
+// the chart's values json schema. Errors are sorted by path.
+func ValidateValues(values, defaults, schema []byte) (*ValuesValidation, error) {
+	vals, err := chartutil.ReadValues(values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValues, err)
+	}
+	defaultVals, err := chartutil.ReadValues(defaults)
+	if err != nil {
+		return nil, fmt.Errorf("error reading default values: %w", err)
+	}
+	valuesJSON, err := json.Marshal(chartutil.CoalesceTables(vals, defaultVals))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValues, err)
+	}
+
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewBytesLoader(schema),
+		gojsonschema.NewBytesLoader(valuesJSON),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValuesSchema, err)
+	}
+	validation := &ValuesValidation{
+		Valid:  result.Valid(),
+		Errors: make([]*ValuesError, 0, len(result.Errors())),
+	}
+	for _, e := range result.Errors() {
+		validation.Errors = append(validation.Errors, &ValuesError{
+			Path:    valuesErrorPath(e),
+			Message: e.Description(),
+		})
+	}
+	sort.SliceStable(validation.Errors, func(i, j int) bool {
+		if validation.Errors[i].Path != validation.Errors[j].Path {
+			return validation.Errors[i].Path < validation.Errors[j].Path
+		}
+		return validation.Errors[i].Message < validation.Errors[j].Message
+	})
+	return validation, nil
+}
+
+// valuesErrorPath returns the dotted path of the key the validation error
+// provided refers to. Errors about missing required properties are reported
+// on the missing property itself.
+func valuesErrorPath(e gojsonschema.ResultError) string {
+	path := e.Field()
+	if path == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+		path = ""
+	}
+	if e.Type() == "required" {
+		if property, ok := e.Details()["property"].(string); ok {
+			path = strings.TrimPrefix(path+"."+property, ".")
+		}
+	}
+	return path
+}
@@ -0,0 +1,85 @@
+package helm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValues(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"required": ["image", "replicaCount"],
+		"properties": {
+			"replicaCount": {"type": "integer"},
+			"image": {
+				"type": "object",
+				"required": ["repository", "tag"],
+				"properties": {
+					"repository": {"type": "string"},
+					"tag": {"type": "string"}
+				}
+			}
+		}
+	}`)
+	defaults := []byte(`
+replicaCount: 1
+image:
+  repository: repo/app
+`)
+
+	t.Run("invalid values", func(t *testing.T) {
+		validation, err := ValidateValues([]byte("key: [value"), defaults, schema)
+		assert.True(t, errors.Is(err, ErrInvalidValues))
+		assert.Nil(t, validation)
+	})
+
+	t.Run("invalid values schema", func(t *testing.T) {
+		validation, err := ValidateValues([]byte("key: value"), defaults, []byte("{"))
+		assert.True(t, errors.Is(err, ErrInvalidValuesSchema))
+		assert.Nil(t, validation)
+	})
+
+	t.Run("valid values (yaml)", func(t *testing.T) {
+		validation, err := ValidateValues([]byte("image:\n  tag: 1.0.0\n"), defaults, schema)
+		assert.NoError(t, err)
+		assert.Equal(t, &ValuesValidation{Valid: true, Errors: []*ValuesError{}}, validation)
+	})
+
+	t.Run("valid values (json)", func(t *testing.T) {
+		validation, err := ValidateValues([]byte(`{"image": {"tag": "1.0.0"}}`), defaults, schema)
+		assert.NoError(t, err)
+		assert.True(t, validation.Valid)
+	})
+
+	t.Run("invalid values merged with defaults", func(t *testing.T) {
+		values := []byte(`
+replicaCount: two
+image:
+  repository: 1
+`)
+		validation, err := ValidateValues(values, defaults, schema)
+		assert.NoError(t, err)
+		assert.Equal(t, &ValuesValidation{
+			Valid: false,
+			Errors: []*ValuesError{
+				{Path: "image.repository", Message: "Invalid type. Expected: string, given: integer"},
+				{Path: "image.tag", Message: "tag is required"},
+				{Path: "replicaCount", Message: "Invalid type. Expected: integer, given: string"},
+			},
+		}, validation)
+	})
+
+	t.Run("no default values", func(t *testing.T) {
+		validation, err := ValidateValues(nil, nil, schema)
+		assert.NoError(t, err)
+		assert.Equal(t, &ValuesValidation{
+			Valid: false,
+			Errors: []*ValuesError{
+				{Path: "image", Message: "image is required"},
+				{Path: "replicaCount", Message: "replicaCount is required"},
+			},
+		}, validation)
+	})
+}
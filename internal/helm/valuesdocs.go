@@ -0,0 +1,162 @@
+package helm
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"gopkg.in/yaml.v3"
+)
+
+// Markers used in the comments documenting values (helm-docs style).
+const (
+	valueDocMarker        = "--"
+	valueDocDefaultMarker = "@default --"
+)
+
+// GetValuesDocs extracts the documentation of the parameters available in the
+// default values (values.yaml) provided. Parameters are documented using
+// helm-docs style comments placed right above the key they describe:
+//
+//	# -- (type) Description of the parameter
+//	# that can span multiple lines
+//	# @default -- value to display as default
+//	key: value
+//
+// Both the type and the default value overrides are optional. Documented keys
+// are listed as a single parameter, even when their values are objects.
+// Undocumented objects are walked, listing their entries, and the remaining
+// undocumented keys are listed with an empty description. Nil is returned when
+// the values provided contain no parameters.
+func GetValuesDocs(data []byte) ([]*hub.ValueDoc, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := resolveAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var params []*hub.ValueDoc
+	walkValuesDocs(root, "", &params)
+	return params, nil
+}
+
+// walkValuesDocs walks the mapping node provided, appending to params the
+// documentation of its entries.
+func walkValuesDocs(n *yaml.Node, prefix string, params *[]*hub.ValueDoc) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], resolveAlias(n.Content[i+1])
+		key := buildValueKey(prefix, k.Value)
+		param, documented := parseValueDocComment(k.HeadComment)
+		if !documented && v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+			walkValuesDocs(v, key, params)
+			continue
+		}
+		param.Key = key
+		if param.Type == "" {
+			param.Type = valueType(v)
+		}
+		if param.Default == "" {
+			param.Default = valueDefault(v)
+		}
+		*params = append(*params, param)
+	}
+}
+
+// parseValueDocComment parses the head comment of a key, returning the
+// documentation found in it (if any). Only the last block starting with the
+// documentation marker is considered, so any comments preceding it (like
+// commented out examples) are ignored.
+func parseValueDocComment(comment string) (*hub.ValueDoc, bool) {
+	param := &hub.ValueDoc{}
+	var description []string
+	documented := false
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		switch {
+		case strings.HasPrefix(line, valueDocDefaultMarker):
+			if documented {
+				param.Default = strings.TrimSpace(strings.TrimPrefix(line, valueDocDefaultMarker))
+			}
+		case line == valueDocMarker || strings.HasPrefix(line, valueDocMarker+" "):
+			documented = true
+			param.Type = ""
+			param.Default = ""
+			description = nil
+			text := strings.TrimSpace(strings.TrimPrefix(line, valueDocMarker))
+			if strings.HasPrefix(text, "(") {
+				if end := strings.Index(text, ")"); end > 0 {
+					param.Type = strings.TrimSpace(text[1:end])
+					text = strings.TrimSpace(text[end+1:])
+				}
+			}
+			if text != "" {
+				description = append(description, text)
+			}
+		default:
+			if documented && line != "" {
+				description = append(description, line)
+			}
+		}
+	}
+	param.Description = strings.Join(description, " ")
+	return param, documented
+}
+
+// buildValueKey builds the dotted path of a key given its parent's path. Keys
+// containing dots are quoted to keep the path unambiguous.
+func buildValueKey(prefix, key string) string {
+	if strings.Contains(key, ".") {
+		key = `"` + key + `"`
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// valueType returns the type of the value node provided.
+func valueType(v *yaml.Node) string {
+	switch v.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	switch v.ShortTag() {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!bool":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// valueDefault returns the value node provided encoded as json.
+func valueDefault(v *yaml.Node) string {
+	var value interface{}
+	if err := v.Decode(&value); err != nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// resolveAlias returns the node an alias node points to. Any other nodes are
+// returned as they are.
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
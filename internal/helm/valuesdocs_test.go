@@ -0,0 +1,79 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetValuesDocs(t *testing.T) {
+	t.Run("invalid values", func(t *testing.T) {
+		params, err := GetValuesDocs([]byte("key: [value"))
+		assert.Error(t, err)
+		assert.Nil(t, params)
+	})
+
+	t.Run("no parameters available", func(t *testing.T) {
+		testCases := []string{
+			"",
+			"# just a comment",
+			"- item",
+			"value",
+		}
+		for _, tc := range testCases {
+			params, err := GetValuesDocs([]byte(tc))
+			assert.NoError(t, err)
+			assert.Nil(t, params)
+		}
+	})
+
+	t.Run("parameters documented", func(t *testing.T) {
+		values := `
+# Default values for chart.
+
+# -- Number of replicas
+replicaCount: 1
+image:
+  # -- Image repository
+  # used by the deployment
+  repository: repo/app
+  # -- Image tag
+  # @default -- the chart appVersion
+  tag: ""
+  pullPolicy: IfNotPresent
+# someKey: this is a commented out example
+# -- (string) Existing secret name
+existingSecret:
+# -- Pod annotations
+podAnnotations:
+  key: value
+nodeSelector: {}
+tolerations: []
+ratio: 0.5
+enabled: true
+base: &base
+  name: base
+# -- Aliased values
+alias: *base
+dotted.key: value
+`
+		params, err := GetValuesDocs([]byte(values))
+		assert.NoError(t, err)
+		assert.Equal(t, []*hub.ValueDoc{
+			{Key: "replicaCount", Type: "int", Default: "1", Description: "Number of replicas"},
+			{Key: "image.repository", Type: "string", Default: `"repo/app"`, Description: "Image repository used by the deployment"},
+			{Key: "image.tag", Type: "string", Default: "the chart appVersion", Description: "Image tag"},
+			{Key: "image.pullPolicy", Type: "string", Default: `"IfNotPresent"`},
+			{Key: "existingSecret", Type: "string", Default: "null", Description: "Existing secret name"},
+			{Key: "podAnnotations", Type: "object", Default: `{"key":"value"}`, Description: "Pod annotations"},
+			{Key: "nodeSelector", Type: "object", Default: "{}"},
+			{Key: "tolerations", Type: "list", Default: "[]"},
+			{Key: "ratio", Type: "float", Default: "0.5"},
+			{Key: "enabled", Type: "bool", Default: "true"},
+			{Key: "base.name", Type: "string", Default: `"base"`},
+			{Key: "alias", Type: "object", Default: `{"name":"base"}`, Description: "Aliased values"},
+			{Key: `"dotted.key"`, Type: "string", Default: `"value"`},
+		}, params)
+	})
+}
@@ -2,6 +2,7 @@ package hub
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/artifacthub/hub/internal/email"
 	"github.com/jackc/pgconn"
@@ -24,13 +25,71 @@ type EmailSender interface {
 	SendEmail(data *email.Data) error
 }
 
-// ChartRepository represents a Helm chart repository.
+// Encrypter defines the methods the encrypter used to protect sensitive data
+// stored in the database must provide.
+type Encrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// ChartRepository represents a Helm chart repository. The tracking interval
+// and jitter are expressed in minutes. The keyring contains the ASCII armored
+// public keys used to verify the provenance files of the repository charts.
+// CredentialsError is set when the repository credentials stored in the
+// database could not be decrypted.
 type ChartRepository struct {
-	ChartRepositoryID string `json:"chart_repository_id"`
-	Name              string `json:"name"`
-	DisplayName       string `json:"display_name"`
-	URL               string `json:"url"`
-	UserID            string `json:"user_id"`
+	ChartRepositoryID string                      `json:"chart_repository_id"`
+	Name              string                      `json:"name"`
+	DisplayName       string                      `json:"display_name"`
+	URL               string                      `json:"url"`
+	UserID            string                      `json:"user_id"`
+	Credentials       *ChartRepositoryCredentials `json:"credentials,omitempty"`
+	TrackingInterval  int                         `json:"tracking_interval,omitempty"`
+	TrackingJitter    int                         `json:"tracking_jitter,omitempty"`
+	IndexCache        *ChartRepositoryIndexCache  `json:"index_cache,omitempty"`
+	Keyring           *string                     `json:"keyring,omitempty"`
+	Hosted            bool                        `json:"hosted"`
+	CredentialsError  error                       `json:"-"`
+}
+
+// ChartRepositoryCredentials represents the credentials and TLS certificates
+// used to access a private chart repository. Certificates and keys are
+// expected to be PEM encoded.
+type ChartRepositoryCredentials struct {
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	Token      string `json:"token,omitempty"`
+	CACert     string `json:"ca_cert,omitempty"`
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+}
+
+// ChartRepositoryIndexCache represents some information about the index file
+// of a chart repository processed successfully the last time it was tracked,
+// used to detect if it has changed since then.
+type ChartRepositoryIndexCache struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256,omitempty"`
+}
+
+// Dependency represents a dependency of a chart, as defined in its metadata.
+type Dependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Condition  string `json:"condition,omitempty"`
+	Alias      string `json:"alias,omitempty"`
+}
+
+// DeprecatedAPI represents a deprecated Kubernetes API version used by a
+// package in its manifests.
+type DeprecatedAPI struct {
+	APIVersion   string `json:"api_version"`
+	Kind         string `json:"kind"`
+	DeprecatedIn string `json:"deprecated_in"`
+	RemovedIn    string `json:"removed_in"`
+	Replacement  string `json:"replacement,omitempty"`
 }
 
 // Link represents a url associated with a package.
@@ -39,6 +98,31 @@ type Link struct {
 	URL  string `json:"url"`
 }
 
+// KubeVersionRange represents a range of Kubernetes versions compatible with a
+// package. Versions are encoded as integers (major*1000000 + minor*1000 +
+// patch) and both bounds are inclusive. A nil bound means the range is
+// unbounded on that side.
+type KubeVersionRange struct {
+	Min *int `json:"min"`
+	Max *int `json:"max"`
+}
+
+// Severities of the lint messages reported when linting a chart.
+const (
+	LintUnknown = "unknown"
+	LintInfo    = "info"
+	LintWarning = "warning"
+	LintError   = "error"
+)
+
+// LintMessage represents a message reported by the Helm linter when linting a
+// chart.
+type LintMessage struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
 // Maintainer represents a package's maintainer.
 type Maintainer struct {
 	MaintainerID string `json:"maintainer_id"`
@@ -75,12 +159,47 @@ type Package struct {
 	Readme            string                 `json:"readme"`
 	Links             []*Link                `json:"links"`
 	Version           string                 `json:"version"`
-	AvailableVersions []string               `json:"available_versions"`
+	AvailableVersions []*AvailableVersion    `json:"available_versions"`
 	AppVersion        string                 `json:"app_version"`
 	Digest            string                 `json:"digest"`
+	ContentURL        string                 `json:"content_url"`
+	ArchiveDigest     string                 `json:"archive_digest"`
+	ChartMetadata     json.RawMessage        `json:"chart_metadata"`
+	ProvenanceDigest  string                 `json:"provenance_digest"`
+	Signed            bool                   `json:"signed"`
+	SignatureVerified bool                   `json:"signature_verified"`
+	Signer            string                 `json:"signer"`
 	Data              map[string]interface{} `json:"data"`
+	DefaultValues     string                 `json:"default_values"`
+	ValuesSchema      json.RawMessage        `json:"values_schema"`
+	ValuesDocs        []*ValueDoc            `json:"values_docs"`
+	Dependencies      []*Dependency          `json:"dependencies"`
+	ContainerImages   []string               `json:"container_images"`
+	KubeVersion       string                 `json:"kube_version"`
+	KubeVersionRanges []*KubeVersionRange    `json:"kube_version_ranges"`
+	DeprecatedAPIs    []*DeprecatedAPI       `json:"deprecated_apis"`
+	LintReport        []*LintMessage         `json:"lint_report"`
+	QualityScore      *int                   `json:"quality_score"`
 	Maintainers       []*Maintainer          `json:"maintainers"`
 	ChartRepository   *ChartRepository       `json:"chart_repository"`
+	Yanked            bool                   `json:"yanked"`
+	YankedReason      string                 `json:"yanked_reason"`
+}
+
+// AvailableVersion represents one of the versions available of a package.
+type AvailableVersion struct {
+	Version string `json:"version"`
+	Yanked  bool   `json:"yanked"`
+}
+
+// ValueDoc represents the documentation of a parameter available in the
+// default values (values.yaml) of a package. The key is the dotted path of the
+// parameter and the default value is json encoded.
+type ValueDoc struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
 }
 
 // User represents a Hub user.
@@ -113,3 +232,68 @@ type Organization struct {
 	LogoURL        string `json:"logo_url"`
 	LogoImageID    string `json:"logo_image_id"`
 }
+
+// TrackingRun represents the results of a chart repository tracking run.
+type TrackingRun struct {
+	ChartRepositoryID string           `json:"chart_repository_id"`
+	StartedAt         int64            `json:"started_at"`
+	FinishedAt        int64            `json:"finished_at"`
+	Processed         int              `json:"processed"`
+	Registered        int              `json:"registered"`
+	Failed            int              `json:"failed"`
+	Errors            []*TrackingError `json:"errors"`
+}
+
+// TrackingErrorKind represents the kind of a given tracking error.
+type TrackingErrorKind string
+
+const (
+	// IndexTrackingError represents an error processing a repository's index.
+	IndexTrackingError TrackingErrorKind = "index"
+
+	// ChartLoadTrackingError represents an error loading a chart archive.
+	ChartLoadTrackingError TrackingErrorKind = "chart_load"
+
+	// InvalidChartTrackingError represents an error caused by some invalid
+	// content in a chart (i.e. an invalid url or values schema).
+	InvalidChartTrackingError TrackingErrorKind = "invalid_chart"
+
+	// ProvenanceTrackingError represents an error loading a repository's
+	// keyring or verifying a chart's provenance file.
+	ProvenanceTrackingError TrackingErrorKind = "provenance"
+
+	// LintTrackingError represents an error reported by the Helm linter
+	// when linting a chart.
+	LintTrackingError TrackingErrorKind = "lint"
+
+	// LogoTrackingError represents an error processing a chart's logo.
+	LogoTrackingError TrackingErrorKind = "logo"
+
+	// MirrorTrackingError represents an error storing a chart archive in the
+	// hub's archive mirror.
+	MirrorTrackingError TrackingErrorKind = "mirror"
+
+	// RegisterTrackingError represents an error registering a chart version.
+	RegisterTrackingError TrackingErrorKind = "register"
+
+	// UnregisterTrackingError represents an error unregistering a chart
+	// version.
+	UnregisterTrackingError TrackingErrorKind = "unregister"
+
+	// CredentialsTrackingError represents an error decrypting the
+	// credentials of a chart repository.
+	CredentialsTrackingError TrackingErrorKind = "credentials"
+
+	// InternalTrackingError represents an unexpected error.
+	InternalTrackingError TrackingErrorKind = "internal"
+)
+
+// TrackingError represents an error found while tracking a chart repository,
+// optionally related to a specific chart version.
+type TrackingError struct {
+	Kind         TrackingErrorKind `json:"kind"`
+	ChartName    string            `json:"chart_name,omitempty"`
+	ChartVersion string            `json:"chart_version,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	Message      string            `json:"message"`
+}
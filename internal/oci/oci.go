@@ -0,0 +1,368 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// Scheme represents the scheme used in the urls of OCI based repositories.
+	Scheme = "oci"
+
+	// manifestMediaType represents the media type of an OCI image manifest.
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// chartLayerMediaTypes represents the media types used by Helm for the layer
+// containing the chart archive in an OCI image manifest.
+var chartLayerMediaTypes = []string{
+	"application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+	"application/tar+gzip",
+}
+
+var (
+	// ErrNotFound indicates that the requested resource was not found in the
+	// registry.
+	ErrNotFound = errors.New("not found")
+
+	// ErrChartLayerNotFound indicates that the manifest requested does not
+	// contain a Helm chart layer.
+	ErrChartLayerNotFound = errors.New("chart layer not found in manifest")
+)
+
+// Reference represents a reference to a repository in an OCI registry,
+// optionally pointing to a given blob using its digest.
+type Reference struct {
+	Registry   string
+	Repository string
+	Digest     string
+}
+
+// ParseReference parses the oci url provided (oci://registry/repository or
+// oci://registry/repository@digest) returning a Reference instance.
+func ParseReference(u string) (*Reference, error) {
+	tmp, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	if tmp.Scheme != Scheme || tmp.Host == "" {
+		return nil, fmt.Errorf("invalid oci url: %s", u)
+	}
+	ref := &Reference{
+		Registry:   tmp.Host,
+		Repository: strings.Trim(tmp.Path, "/"),
+	}
+	if i := strings.Index(ref.Repository, "@"); i >= 0 {
+		ref.Digest = ref.Repository[i+1:]
+		ref.Repository = ref.Repository[:i]
+	}
+	return ref, nil
+}
+
+// IsOCI checks if the url provided uses the oci scheme.
+func IsOCI(u string) bool {
+	return strings.HasPrefix(u, Scheme+"://")
+}
+
+// String returns the oci url representation of the reference.
+func (r *Reference) String() string {
+	s := fmt.Sprintf("%s://%s/%s", Scheme, r.Registry, r.Repository)
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Client is a minimal OCI distribution API client able to list the charts
+// available in a registry and pull their archives.
+type Client struct {
+	httpClient *http.Client
+	username   string
+	password   string
+}
+
+// NewClient creates a new Client instance. The username and password provided
+// (if any) will be used when requesting tokens to the registry authorization
+// service, as long as it is hosted in the registry host.
+func NewClient(httpClient *http.Client, username, password string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		username:   username,
+		password:   password,
+	}
+}
+
+// Catalog returns the repositories available in the registry provided whose
+// name starts with the prefix given.
+func (c *Client) Catalog(ctx context.Context, registry, prefix string) ([]string, error) {
+	var repositories []string
+	u := registryURL(registry, "/v2/_catalog?n=1000")
+	for u != "" {
+		var result struct {
+			Repositories []string `json:"repositories"`
+		}
+		resp, err := c.get(ctx, u, "application/json")
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range result.Repositories {
+			if prefix == "" || strings.HasPrefix(name, prefix+"/") {
+				repositories = append(repositories, name)
+			}
+		}
+		u = nextPageURL(registry, resp.Header.Get("Link"))
+	}
+	return repositories, nil
+}
+
+// Tags returns the tags available for the repository provided.
+func (c *Client) Tags(ctx context.Context, ref *Reference) ([]string, error) {
+	var tags []string
+	u := registryURL(ref.Registry, fmt.Sprintf("/v2/%s/tags/list", ref.Repository))
+	for u != "" {
+		var result struct {
+			Tags []string `json:"tags"`
+		}
+		resp, err := c.get(ctx, u, "application/json")
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, result.Tags...)
+		u = nextPageURL(ref.Registry, resp.Header.Get("Link"))
+	}
+	return tags, nil
+}
+
+// ChartLayerDigest returns the digest of the layer containing the chart
+// archive in the manifest identified by the tag provided.
+func (c *Client) ChartLayerDigest(ctx context.Context, ref *Reference, tag string) (string, error) {
+	u := registryURL(ref.Registry, fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, tag))
+	resp, err := c.get(ctx, u, manifestMediaType)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return "", err
+	}
+	for _, layer := range manifest.Layers {
+		for _, mediaType := range chartLayerMediaTypes {
+			if layer.MediaType == mediaType {
+				return layer.Digest, nil
+			}
+		}
+	}
+	return "", ErrChartLayerNotFound
+}
+
+// PullBlob returns a reader for the content of the blob identified by the
+// reference provided. The caller is responsible for closing it.
+func (c *Client) PullBlob(ctx context.Context, ref *Reference) (io.ReadCloser, error) {
+	if ref.Digest == "" {
+		return nil, errors.New("blob digest not provided")
+	}
+	u := registryURL(ref.Registry, fmt.Sprintf("/v2/%s/blobs/%s", ref.Repository, ref.Digest))
+	resp, err := c.get(ctx, u, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// get performs a GET request to the url provided. If the registry requests
+// authentication using a bearer token, a token will be requested (anonymously
+// unless the client has credentials) and the request will be retried using it.
+func (c *Client) get(ctx context.Context, u, accept string) (*http.Response, error) {
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		return req, nil
+	}
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := c.getToken(ctx, req.URL, challenge)
+		if err != nil {
+			return nil, err
+		}
+		req, err = newRequest()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+}
+
+// getToken requests a token to the authorization service described in the
+// bearer challenge provided, received from the registry url given. The
+// authorization service must use https, unless the registry does not use it
+// either (local registries), and the client credentials are only sent to it
+// when it is hosted in the registry host.
+func (c *Client) getToken(ctx context.Context, registry *url.URL, challenge string) (string, error) {
+	params := parseBearerChallenge(challenge)
+	if params["realm"] == "" {
+		return "", fmt.Errorf("unsupported authentication challenge: %s", challenge)
+	}
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" && u.Scheme != registry.Scheme {
+		return "", fmt.Errorf("insecure authorization service realm: %s", params["realm"])
+	}
+	qs := u.Query()
+	for _, p := range []string{"service", "scope"} {
+		if params[p] != "" {
+			qs.Set(p, params[p])
+		}
+	}
+	u.RawQuery = qs.Encode()
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.username != "" && u.Host == registry.Host {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code received requesting token: %d", resp.StatusCode)
+	}
+	var result struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	return result.AccessToken, nil
+}
+
+// parseBearerChallenge parses the parameters of a WWW-Authenticate bearer
+// challenge (Bearer realm="...",service="...",scope="...").
+func parseBearerChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return params
+	}
+	rest := challenge[len("bearer "):]
+	for rest != "" {
+		i := strings.Index(rest, "=")
+		if i < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:i]))
+		rest = rest[i+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			j := strings.Index(rest[1:], `"`)
+			if j < 0 {
+				break
+			}
+			value = rest[1 : j+1]
+			rest = rest[j+2:]
+		} else {
+			j := strings.Index(rest, ",")
+			if j < 0 {
+				j = len(rest)
+			}
+			value = rest[:j]
+			rest = rest[j:]
+		}
+		params[key] = value
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return params
+}
+
+// registryURL builds the url of the registry endpoint provided. Registries
+// running on the loopback interface are accessed using plain http.
+func registryURL(registry, endpoint string) string {
+	scheme := "https"
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		scheme = "http"
+	} else if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, registry, endpoint)
+}
+
+// nextPageURL extracts the url of the next page of results from the Link
+// header provided (<url>; rel="next"), if any.
+func nextPageURL(registry, link string) string {
+	if link == "" || !strings.Contains(link, `rel="next"`) {
+		return ""
+	}
+	start := strings.Index(link, "<")
+	end := strings.Index(link, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	next := link[start+1 : end]
+	if strings.HasPrefix(next, "/") {
+		return registryURL(registry, next)
+	}
+	return next
+}
@@ -0,0 +1,199 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Run("valid references", func(t *testing.T) {
+		testCases := []struct {
+			u           string
+			expectedRef *Reference
+		}{
+			{
+				"oci://registry.io",
+				&Reference{Registry: "registry.io"},
+			},
+			{
+				"oci://registry.io/charts/",
+				&Reference{Registry: "registry.io", Repository: "charts"},
+			},
+			{
+				"oci://localhost:5000/charts/chart1@sha256:abc",
+				&Reference{Registry: "localhost:5000", Repository: "charts/chart1", Digest: "sha256:abc"},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.u, func(t *testing.T) {
+				ref, err := ParseReference(tc.u)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedRef, ref)
+			})
+		}
+	})
+
+	t.Run("invalid references", func(t *testing.T) {
+		for _, u := range []string{"https://registry.io/charts", "oci://", "oci:///charts"} {
+			_, err := ParseReference(u)
+			assert.Error(t, err, u)
+		}
+	})
+}
+
+func TestReferenceString(t *testing.T) {
+	ref := &Reference{Registry: "localhost:5000", Repository: "charts/chart1"}
+	assert.Equal(t, "oci://localhost:5000/charts/chart1", ref.String())
+	ref.Digest = "sha256:abc"
+	assert.Equal(t, "oci://localhost:5000/charts/chart1@sha256:abc", ref.String())
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	params := parseBearerChallenge(`Bearer realm="https://auth.io/token",service="registry.io",scope="repository:charts/chart1:pull"`)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.io/token",
+		"service": "registry.io",
+		"scope":   "repository:charts/chart1:pull",
+	}, params)
+	assert.Empty(t, parseBearerChallenge(`Basic realm="registry"`))
+}
+
+func TestRegistryURL(t *testing.T) {
+	assert.Equal(t, "https://registry.io/v2/", registryURL("registry.io", "/v2/"))
+	assert.Equal(t, "http://localhost:5000/v2/", registryURL("localhost:5000", "/v2/"))
+	assert.Equal(t, "http://127.0.0.1:5000/v2/", registryURL("127.0.0.1:5000", "/v2/"))
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	s, registry := setupFakeRegistry()
+	defer s.Close()
+	c := NewClient(s.Client(), "user", "pass")
+
+	t.Run("catalog", func(t *testing.T) {
+		repositories, err := c.Catalog(ctx, registry, "charts")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"charts/chart1", "charts/chart2"}, repositories)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		tags, err := c.Tags(ctx, &Reference{Registry: registry, Repository: "charts/chart1"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, tags)
+	})
+
+	t.Run("tags of non existing repository", func(t *testing.T) {
+		_, err := c.Tags(ctx, &Reference{Registry: registry, Repository: "charts"})
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("chart layer digest", func(t *testing.T) {
+		ref := &Reference{Registry: registry, Repository: "charts/chart1"}
+		digest, err := c.ChartLayerDigest(ctx, ref, "1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:chart1-1.0.0", digest)
+	})
+
+	t.Run("manifest without chart layer", func(t *testing.T) {
+		ref := &Reference{Registry: registry, Repository: "charts/chart2"}
+		_, err := c.ChartLayerDigest(ctx, ref, "1.0.0")
+		assert.Equal(t, ErrChartLayerNotFound, err)
+	})
+
+	t.Run("pull blob", func(t *testing.T) {
+		ref := &Reference{Registry: registry, Repository: "charts/chart1", Digest: "sha256:chart1-1.0.0"}
+		blob, err := c.PullBlob(ctx, ref)
+		require.NoError(t, err)
+		defer blob.Close()
+		data, _ := ioutil.ReadAll(blob)
+		assert.Equal(t, []byte("chart1-1.0.0-archive"), data)
+	})
+}
+
+func TestClientAuthorizationServiceRealm(t *testing.T) {
+	ctx := context.Background()
+	var credentialsReceived bool
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, credentialsReceived = r.BasicAuth()
+		fmt.Fprint(w, `{"token": "fake-token"}`)
+	}))
+	defer auth.Close()
+	registryHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fake-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token"`, auth.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"name": "charts/chart1", "tags": ["1.0.0"]}`)
+	})
+
+	t.Run("credentials not sent to authorization services hosted elsewhere", func(t *testing.T) {
+		s := httptest.NewServer(registryHandler)
+		defer s.Close()
+		c := NewClient(s.Client(), "user", "pass")
+		ref := &Reference{Registry: strings.TrimPrefix(s.URL, "http://"), Repository: "charts/chart1"}
+		tags, err := c.Tags(ctx, ref)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.0.0"}, tags)
+		assert.False(t, credentialsReceived)
+	})
+
+	t.Run("insecure authorization service realm rejected", func(t *testing.T) {
+		s := httptest.NewTLSServer(registryHandler)
+		defer s.Close()
+		c := NewClient(s.Client(), "user", "pass")
+		_, err := c.get(ctx, s.URL+"/v2/charts/chart1/tags/list", "")
+		assert.Contains(t, err.Error(), "insecure authorization service realm")
+	})
+}
+
+// setupFakeRegistry launches a fake OCI registry that requires a bearer token,
+// returning the server and the registry address.
+func setupFakeRegistry() (*httptest.Server, string) {
+	mux := http.NewServeMux()
+	var registry string
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token": "fake-token"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fake-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="fake"`, registry))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/_catalog" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/_catalog?n=1000&last=charts/chart1>; rel="next"`)
+			fmt.Fprint(w, `{"repositories": ["charts/chart1", "other/image"]}`)
+		case r.URL.Path == "/v2/_catalog":
+			fmt.Fprint(w, `{"repositories": ["charts/chart2"]}`)
+		case r.URL.Path == "/v2/charts/chart1/tags/list":
+			fmt.Fprint(w, `{"name": "charts/chart1", "tags": ["1.0.0", "1.1.0"]}`)
+		case r.URL.Path == "/v2/charts/chart1/manifests/1.0.0":
+			fmt.Fprint(w, `{"layers": [{"mediaType": "application/tar+gzip", "digest": "sha256:chart1-1.0.0"}]}`)
+		case r.URL.Path == "/v2/charts/chart2/manifests/1.0.0":
+			fmt.Fprint(w, `{"layers": [{"mediaType": "application/octet-stream", "digest": "sha256:other"}]}`)
+		case r.URL.Path == "/v2/charts/chart1/blobs/sha256:chart1-1.0.0":
+			fmt.Fprint(w, "chart1-1.0.0-archive")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	s := httptest.NewServer(mux)
+	registry = strings.TrimPrefix(s.URL, "http://")
+
+	return s, registry
+}
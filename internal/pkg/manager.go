@@ -3,10 +3,21 @@ package pkg
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/jackc/pgconn"
 )
 
+// ErrVersionAlreadyExists indicates that the package version provided cannot
+// be registered because it already exists and it cannot be overwritten, as it
+// happens with the packages of hosted chart repositories.
+var ErrVersionAlreadyExists = errors.New("package version already exists")
+
+// uniqueViolationErrCode represents the error code raised by the database when
+// a unique constraint is violated.
+const uniqueViolationErrCode = "23505"
+
 // Manager provides an API to manage packages.
 type Manager struct {
 	db hub.DB
@@ -19,6 +30,29 @@ func NewManager(db hub.DB) *Manager {
 	}
 }
 
+// GetContent returns the information needed to fetch the content (chart
+// archive) of the package version identified by the input provided.
+func (m *Manager) GetContent(ctx context.Context, input *GetInput) (*Content, error) {
+	inputJSON, _ := json.Marshal(input)
+	jsonData, err := m.dbQueryJSON(ctx, "select get_package_content($1::jsonb)", inputJSON)
+	if err != nil {
+		return nil, err
+	}
+	var c *Content
+	if err := json.Unmarshal(jsonData, &c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetDependentsJSON returns the packages whose latest version depends on the
+// chart identified by the input provided as a json array. The json array is
+// built by the database.
+func (m *Manager) GetDependentsJSON(ctx context.Context, input *GetInput) ([]byte, error) {
+	inputJSON, _ := json.Marshal(input)
+	return m.dbQueryJSON(ctx, "select get_package_dependents($1::jsonb)", inputJSON)
+}
+
 // GetJSON returns the package identified by the input provided as a json
 // object. The json object is built by the database.
 func (m *Manager) GetJSON(ctx context.Context, input *GetInput) ([]byte, error) {
@@ -39,9 +73,38 @@ func (m *Manager) GetUpdatesJSON(ctx context.Context) ([]byte, error) {
 	return m.dbQueryJSON(ctx, "select get_packages_updates()")
 }
 
-// Register registers the package provided in the database.
+// GetValuesDocsJSON returns the documentation of the parameters available in
+// the default values (values.yaml) of the package version identified by the
+// input provided as a json array.
+func (m *Manager) GetValuesDocsJSON(ctx context.Context, input *GetInput) ([]byte, error) {
+	inputJSON, _ := json.Marshal(input)
+	return m.dbQueryJSON(ctx, "select get_package_values_docs($1::jsonb)", inputJSON)
+}
+
+// GetValuesSchemaJSON returns the values json schema (values.schema.json) of
+// the package version identified by the input provided.
+func (m *Manager) GetValuesSchemaJSON(ctx context.Context, input *GetInput) ([]byte, error) {
+	inputJSON, _ := json.Marshal(input)
+	return m.dbQueryJSON(ctx, "select get_package_values_schema($1::jsonb)", inputJSON)
+}
+
+// GetValuesYAML returns the default values (values.yaml) of the package
+// version identified by the input provided.
+func (m *Manager) GetValuesYAML(ctx context.Context, input *GetInput) ([]byte, error) {
+	inputJSON, _ := json.Marshal(input)
+	return m.dbQueryJSON(ctx, "select get_package_values($1::jsonb)", inputJSON)
+}
+
+// Register registers the package provided in the database. Packages versions
+// of hosted chart repositories cannot be overwritten, so registering an
+// existing one returns ErrVersionAlreadyExists.
 func (m *Manager) Register(ctx context.Context, pkg *hub.Package) error {
-	return m.dbExec(ctx, "select register_package($1::jsonb)", pkg)
+	err := m.dbExec(ctx, "select register_package($1::jsonb)", pkg)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationErrCode {
+		return ErrVersionAlreadyExists
+	}
+	return err
 }
 
 // SearchJSON returns a json object with the search results produced by the
@@ -51,6 +114,35 @@ func (m *Manager) SearchJSON(ctx context.Context, input *SearchInput) ([]byte, e
 	return m.dbQueryJSON(ctx, "select search_packages($1::jsonb)", inputJSON)
 }
 
+// Unregister unregisters the package version provided from the database. If
+// the package has no versions left, it is deleted as well.
+func (m *Manager) Unregister(ctx context.Context, pkg *hub.Package) error {
+	return m.dbExec(ctx, "select unregister_package($1::jsonb)", pkg)
+}
+
+// Unyank reverts the yanking of the package version identified by the input
+// provided. The user doing the request must own the chart repository or
+// belong to the organization which owns it.
+func (m *Manager) Unyank(ctx context.Context, input *GetInput) error {
+	query := "select unyank_package_version($1::uuid, $2::jsonb)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	inputJSON, _ := json.Marshal(input)
+	_, err := m.db.Exec(ctx, query, userID, inputJSON)
+	return err
+}
+
+// Yank marks the package version identified by the input provided as yanked,
+// excluding it from the package's latest version computation. The user doing
+// the request must own the chart repository or belong to the organization
+// which owns it.
+func (m *Manager) Yank(ctx context.Context, input *YankInput) error {
+	query := "select yank_package_version($1::uuid, $2::jsonb)"
+	userID := ctx.Value(hub.UserIDKey).(string)
+	inputJSON, _ := json.Marshal(input)
+	_, err := m.db.Exec(ctx, query, userID, inputJSON)
+	return err
+}
+
 // dbQueryJSON is a helper that executes the query provided and returns a bytes
 // slice containing the json data returned from the database.
 func (m *Manager) dbQueryJSON(ctx context.Context, query string, args ...interface{}) ([]byte, error) {
@@ -72,6 +164,16 @@ func (m *Manager) dbExec(ctx context.Context, query string, arg interface{}) err
 	return err
 }
 
+// Content represents the information needed to fetch the content (chart
+// archive) of a package version.
+type Content struct {
+	ChartRepositoryName string `json:"chart_repository_name"`
+	URL                 string `json:"url"`
+	Digest              string `json:"digest"`
+	ArchiveDigest       string `json:"archive_digest"`
+	ProvenanceDigest    string `json:"provenance_digest"`
+}
+
 // GetInput represents the input used to get a specific package.
 type GetInput struct {
 	ChartRepositoryName string `json:"chart_repository_name"`
@@ -88,4 +190,13 @@ type SearchInput struct {
 	PackageKinds      []hub.PackageKind `json:"package_kinds,omitempty"`
 	ChartRepositories []string          `json:"chart_repositories,omitempty"`
 	Deprecated        bool              `json:"deprecated"`
+	Signed            bool              `json:"signed"`
+	Images            []string          `json:"images,omitempty"`
+	KubeVersion       int               `json:"kube_version,omitempty"`
+}
+
+// YankInput represents the input used to yank a specific package version.
+type YankInput struct {
+	GetInput
+	Reason string `json:"reason,omitempty"`
 }
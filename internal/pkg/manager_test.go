@@ -6,10 +6,77 @@ import (
 
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func TestGetContent(t *testing.T) {
+	dbQuery := "select get_package_content($1::jsonb)"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte(`
+		{
+			"chart_repository_name": "repo1",
+			"url": "https://repo1.com/package1-1.0.0.tgz",
+			"digest": "digest",
+			"archive_digest": "archiveDigest",
+			"provenance_digest": "provenanceDigest"
+		}
+		`), nil)
+		m := NewManager(db)
+
+		c, err := m.GetContent(context.Background(), &GetInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, &Content{
+			ChartRepositoryName: "repo1",
+			URL:                 "https://repo1.com/package1-1.0.0.tgz",
+			Digest:              "digest",
+			ArchiveDigest:       "archiveDigest",
+			ProvenanceDigest:    "provenanceDigest",
+		}, c)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		c, err := m.GetContent(context.Background(), &GetInput{})
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, c)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetDependentsJSON(t *testing.T) {
+	dbQuery := "select get_package_dependents($1::jsonb)"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependentsJSON(context.Background(), &GetInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependentsJSON(context.Background(), &GetInput{})
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetJSON(t *testing.T) {
 	dbQuery := "select get_package($1::jsonb)"
 
@@ -88,6 +155,84 @@ func TestGetUpdatesJSON(t *testing.T) {
 	})
 }
 
+func TestGetValuesDocsJSON(t *testing.T) {
+	dbQuery := "select get_package_values_docs($1::jsonb)"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		data, err := m.GetValuesDocsJSON(context.Background(), &GetInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), data)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		data, err := m.GetValuesDocsJSON(context.Background(), &GetInput{})
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, data)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetValuesSchemaJSON(t *testing.T) {
+	dbQuery := "select get_package_values_schema($1::jsonb)"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		data, err := m.GetValuesSchemaJSON(context.Background(), &GetInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), data)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		data, err := m.GetValuesSchemaJSON(context.Background(), &GetInput{})
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, data)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetValuesYAML(t *testing.T) {
+	dbQuery := "select get_package_values($1::jsonb)"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataYAML"), nil)
+		m := NewManager(db)
+
+		data, err := m.GetValuesYAML(context.Background(), &GetInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataYAML"), data)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		data, err := m.GetValuesYAML(context.Background(), &GetInput{})
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, data)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestRegister(t *testing.T) {
 	dbQuery := "select register_package($1::jsonb)"
 
@@ -142,6 +287,16 @@ func TestRegister(t *testing.T) {
 		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
 		db.AssertExpectations(t)
 	})
+
+	t.Run("package version already exists", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(&pgconn.PgError{Code: uniqueViolationErrCode})
+		m := NewManager(db)
+
+		err := m.Register(context.Background(), p)
+		assert.Equal(t, ErrVersionAlreadyExists, err)
+		db.AssertExpectations(t)
+	})
 }
 
 func TestSearchJSON(t *testing.T) {
@@ -170,3 +325,115 @@ func TestSearchJSON(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 }
+
+func TestUnregister(t *testing.T) {
+	dbQuery := "select unregister_package($1::jsonb)"
+
+	p := &hub.Package{
+		Kind:    hub.Chart,
+		Name:    "package1",
+		Version: "1.0.0",
+		ChartRepository: &hub.ChartRepository{
+			ChartRepositoryID: "00000000-0000-0000-0000-000000000001",
+		},
+	}
+
+	t.Run("successful package unregistration", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(nil)
+		m := NewManager(db)
+
+		err := m.Unregister(context.Background(), p)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.Unregister(context.Background(), p)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestUnyank(t *testing.T) {
+	dbQuery := "select unyank_package_version($1::uuid, $2::jsonb)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+	input := &GetInput{
+		ChartRepositoryName: "repo1",
+		PackageName:         "package1",
+		Version:             "1.0.0",
+	}
+	inputJSON := []byte(`{"chart_repository_name":"repo1","package_name":"package1","version":"1.0.0"}`)
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil)
+		assert.Panics(t, func() {
+			_ = m.Unyank(context.Background(), input)
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", inputJSON).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.Unyank(ctx, input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("unyank package version succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", inputJSON).Return(nil)
+		m := NewManager(db)
+
+		err := m.Unyank(ctx, input)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestYank(t *testing.T) {
+	dbQuery := "select yank_package_version($1::uuid, $2::jsonb)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+	input := &YankInput{
+		GetInput: GetInput{
+			ChartRepositoryName: "repo1",
+			PackageName:         "package1",
+			Version:             "1.0.0",
+		},
+		Reason: "broken release",
+	}
+	inputJSON := []byte(`{"chart_repository_name":"repo1","package_name":"package1","version":"1.0.0","reason":"broken release"}`)
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil)
+		assert.Panics(t, func() {
+			_ = m.Yank(context.Background(), input)
+		})
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", inputJSON).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.Yank(ctx, input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("yank package version succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", inputJSON).Return(nil)
+		m := NewManager(db)
+
+		err := m.Yank(ctx, input)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"errors"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// ErrFakeEncrypterFailure represents a fake encrypter failure.
+var ErrFakeEncrypterFailure = errors.New("fake encrypter failure")
+
+// EncrypterMock is a mock implementation of the Encrypter interface.
+type EncrypterMock struct {
+	mock.Mock
+}
+
+// Encrypt implements the Encrypter interface.
+func (m *EncrypterMock) Encrypt(plaintext []byte) ([]byte, error) {
+	args := m.Called(plaintext)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// Decrypt implements the Encrypter interface.
+func (m *EncrypterMock) Decrypt(ciphertext []byte) ([]byte, error) {
+	args := m.Called(ciphertext)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"errors"
+
+	"github.com/artifacthub/hub/internal/archive"
+	"github.com/artifacthub/hub/internal/archive/pg"
+	"github.com/spf13/viper"
+)
+
+// SetupArchiveStore creates a new archive store based on the configuration
+// provided. When no archive store is configured, charts archives are not
+// mirrored and nil is returned.
+func SetupArchiveStore(cfg *viper.Viper, db pg.DB) (archive.Store, error) {
+	archiveStore := cfg.GetString("tracker.archiveStore")
+	switch archiveStore {
+	case "":
+		return nil, nil
+	case "pg":
+		return pg.NewArchiveStore(db), nil
+	default:
+		return nil, errors.New("invalid archive store")
+	}
+}
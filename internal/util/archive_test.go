@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupArchiveStore(t *testing.T) {
+	// Check a valid archive store provider must be provided
+	cfg := viper.New()
+	cfg.Set("tracker.archiveStore", "invalid")
+	archiveStore, err := SetupArchiveStore(cfg, nil)
+	require.Error(t, err)
+	require.Nil(t, archiveStore)
+
+	// Check no archive store is setup when mirroring is disabled
+	cfg = viper.New()
+	archiveStore, err = SetupArchiveStore(cfg, nil)
+	require.NoError(t, err)
+	require.Nil(t, archiveStore)
+
+	// Check archive store was setup successfully
+	cfg = viper.New()
+	cfg.Set("tracker.archiveStore", "pg")
+	archiveStore, err = SetupArchiveStore(cfg, nil)
+	require.NoError(t, err)
+	require.NotNil(t, archiveStore)
+}
@@ -2,7 +2,7 @@ import isUndefined from 'lodash/isUndefined';
 import React from 'react';
 import * as semver from 'semver';
 
-import { Package, PackageKind, SearchFiltersURL } from '../../types';
+import { AvailableVersion, Package, PackageKind, SearchFiltersURL } from '../../types';
 import ChartDetails from './ChartDetails';
 import DefaultDetails from './DefaultDetails';
 import Version from './Version';
@@ -16,10 +16,10 @@ const Details = (props: Props) => {
   const { availableVersions } = props.package;
   const getSortedVersions = () => {
     if (!isUndefined(availableVersions)) {
-      const validVersions = availableVersions.filter((version: string) => semver.valid(version));
-      const invalidVersions = availableVersions.filter((version: string) => !semver.valid(version));
+      const validVersions = availableVersions.filter((av: AvailableVersion) => semver.valid(av.version));
+      const invalidVersions = availableVersions.filter((av: AvailableVersion) => !semver.valid(av.version));
       try {
-        return [...semver.rsort(validVersions), ...invalidVersions];
+        return [...validVersions.sort((a, b) => semver.rcompare(a.version, b.version)), ...invalidVersions];
       } catch {
         return availableVersions;
       }
@@ -27,14 +27,15 @@ const Details = (props: Props) => {
     return [];
   };
 
-  const allVersions: JSX.Element[] = getSortedVersions().map((av_version: string) => (
+  const allVersions: JSX.Element[] = getSortedVersions().map((av: AvailableVersion) => (
     <Version
-      key={av_version}
-      isActive={av_version === props.package.version}
-      version={av_version}
+      key={av.version}
+      isActive={av.version === props.package.version}
+      version={av.version}
+      yanked={av.yanked}
       packageItem={{
         ...props.package,
-        version: av_version,
+        version: av.version,
       }}
       searchUrlReferer={props.searchUrlReferer}
     />
@@ -7,6 +7,7 @@ import buildPackageURL from '../../utils/buildPackageURL';
 interface Props {
   isActive: boolean;
   version: string;
+  yanked?: boolean;
   packageItem: Package;
   searchUrlReferer: SearchFiltersURL | null;
 }
@@ -34,7 +35,10 @@ const Version = (props: Props) => {
   return (
     <div className="py-1 py-sm-0">
       {props.isActive ? (
-        <div className="d-flex align-items-center text-truncate">{props.version}</div>
+        <div className="d-flex align-items-center text-truncate">
+          {props.version}
+          {props.yanked && <small className="text-muted ml-2">(yanked)</small>}
+        </div>
       ) : (
         <button
           data-testid="version"
@@ -42,6 +46,7 @@ const Version = (props: Props) => {
           className="btn btn-link pl-0 pt-0 pb-0 text-truncate d-block"
         >
           {props.version}
+          {props.yanked && <small className="text-muted ml-2">(yanked)</small>}
 
           {isLoading && <span className="spinner-border spinner-border-sm ml-2" />}
         </button>
@@ -17,6 +17,11 @@ export interface Maintainer {
   email: string;
 }
 
+export interface AvailableVersion {
+  version: string;
+  yanked: boolean;
+}
+
 export interface Package {
   packageId: string;
   kind: PackageKind;
@@ -29,8 +34,10 @@ export interface Package {
   chartRepository: ChartRepository | null;
   readme?: string | null;
   data?: PackageData | null;
-  availableVersions?: string[];
+  availableVersions?: AvailableVersion[];
   version?: string;
+  yanked?: boolean;
+  yankedReason?: string | null;
   homeUrl?: string | null;
   keywords?: string[];
   maintainers?: Maintainer[];